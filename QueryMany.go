@@ -0,0 +1,291 @@
+package ecs
+
+import "iter"
+
+// Iterate over all entities that have component A,
+// yielding a pointer to the component so it can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead] to only read
+func Query[A any](p *Pool) iter.Seq2[Entity, *A] {
+	a := GetStorage[A](p)
+	return func(yield func(Entity, *A) bool) {
+		for e := range a.IterAll() {
+			if !yield(e, a.ptr(e.Index())) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query2]
+type Row2[A any, B any] struct {
+	A *A
+	B *B
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead2] to only read
+func Query2[A any, B any](p *Pool) iter.Seq2[Entity, Row2[A, B]] {
+	a, b := GetStorage2[A, B](p)
+	return func(yield func(Entity, Row2[A, B]) bool) {
+		for ent := range a.IterAnd(b) {
+			id := ent.Index()
+			row := Row2[A, B]{
+				a.ptr(id),
+				b.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query3]
+type Row3[A any, B any, C any] struct {
+	A *A
+	B *B
+	C *C
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead3] to only read
+func Query3[A any, B any, C any](p *Pool) iter.Seq2[Entity, Row3[A, B, C]] {
+	a, b, c := GetStorage3[A, B, C](p)
+	return func(yield func(Entity, Row3[A, B, C]) bool) {
+		for ent := range a.IterAnd(b, c) {
+			id := ent.Index()
+			row := Row3[A, B, C]{
+				a.ptr(id),
+				b.ptr(id),
+				c.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query4]
+type Row4[A any, B any, C any, D any] struct {
+	A *A
+	B *B
+	C *C
+	D *D
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead4] to only read
+func Query4[A any, B any, C any, D any](p *Pool) iter.Seq2[Entity, Row4[A, B, C, D]] {
+	a, b, c, d := GetStorage4[A, B, C, D](p)
+	return func(yield func(Entity, Row4[A, B, C, D]) bool) {
+		for ent := range a.IterAnd(b, c, d) {
+			id := ent.Index()
+			row := Row4[A, B, C, D]{
+				a.ptr(id),
+				b.ptr(id),
+				c.ptr(id),
+				d.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query5]
+type Row5[A any, B any, C any, D any, E any] struct {
+	A *A
+	B *B
+	C *C
+	D *D
+	E *E
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead5] to only read
+func Query5[A any, B any, C any, D any, E any](p *Pool) iter.Seq2[Entity, Row5[A, B, C, D, E]] {
+	a, b, c, d, e := GetStorage5[A, B, C, D, E](p)
+	return func(yield func(Entity, Row5[A, B, C, D, E]) bool) {
+		for ent := range a.IterAnd(b, c, d, e) {
+			id := ent.Index()
+			row := Row5[A, B, C, D, E]{
+				a.ptr(id),
+				b.ptr(id),
+				c.ptr(id),
+				d.ptr(id),
+				e.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query6]
+type Row6[A any, B any, C any, D any, E any, F any] struct {
+	A *A
+	B *B
+	C *C
+	D *D
+	E *E
+	F *F
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead6] to only read
+func Query6[A any, B any, C any, D any, E any, F any](p *Pool) iter.Seq2[Entity, Row6[A, B, C, D, E, F]] {
+	a, b, c, d, e, f := GetStorage6[A, B, C, D, E, F](p)
+	return func(yield func(Entity, Row6[A, B, C, D, E, F]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f) {
+			id := ent.Index()
+			row := Row6[A, B, C, D, E, F]{
+				a.ptr(id),
+				b.ptr(id),
+				c.ptr(id),
+				d.ptr(id),
+				e.ptr(id),
+				f.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query7]
+type Row7[A any, B any, C any, D any, E any, F any, G any] struct {
+	A *A
+	B *B
+	C *C
+	D *D
+	E *E
+	F *F
+	G *G
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead7] to only read
+func Query7[A any, B any, C any, D any, E any, F any, G any](p *Pool) iter.Seq2[Entity, Row7[A, B, C, D, E, F, G]] {
+	a, b, c, d, e, f, g := GetStorage7[A, B, C, D, E, F, G](p)
+	return func(yield func(Entity, Row7[A, B, C, D, E, F, G]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f, g) {
+			id := ent.Index()
+			row := Row7[A, B, C, D, E, F, G]{
+				a.ptr(id),
+				b.ptr(id),
+				c.ptr(id),
+				d.ptr(id),
+				e.ptr(id),
+				f.ptr(id),
+				g.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query8]
+type Row8[A any, B any, C any, D any, E any, F any, G any, H any] struct {
+	A *A
+	B *B
+	C *C
+	D *D
+	E *E
+	F *F
+	G *G
+	H *H
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead8] to only read
+func Query8[A any, B any, C any, D any, E any, F any, G any, H any](p *Pool) iter.Seq2[Entity, Row8[A, B, C, D, E, F, G, H]] {
+	a, b, c, d, e, f, g, h := GetStorage8[A, B, C, D, E, F, G, H](p)
+	return func(yield func(Entity, Row8[A, B, C, D, E, F, G, H]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f, g, h) {
+			id := ent.Index()
+			row := Row8[A, B, C, D, E, F, G, H]{
+				a.ptr(id),
+				b.ptr(id),
+				c.ptr(id),
+				d.ptr(id),
+				e.ptr(id),
+				f.ptr(id),
+				g.ptr(id),
+				h.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// pointers to the components of an entity yielded by [Query9]
+type Row9[A any, B any, C any, D any, E any, F any, G any, H any, I any] struct {
+	A *A
+	B *B
+	C *C
+	D *D
+	E *E
+	F *F
+	G *G
+	H *H
+	I *I
+}
+
+// Iterate over all entities that have every one of the components,
+// yielding pointers to the components so they can be modified in place.
+//
+// every yielded component counts as a change for [Changed], so this is a write
+// for the scheduler. use [QueryRead9] to only read
+func Query9[A any, B any, C any, D any, E any, F any, G any, H any, I any](p *Pool) iter.Seq2[Entity, Row9[A, B, C, D, E, F, G, H, I]] {
+	a, b, c, d, e, f, g, h, i := GetStorage9[A, B, C, D, E, F, G, H, I](p)
+	return func(yield func(Entity, Row9[A, B, C, D, E, F, G, H, I]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f, g, h, i) {
+			id := ent.Index()
+			row := Row9[A, B, C, D, E, F, G, H, I]{
+				a.ptr(id),
+				b.ptr(id),
+				c.ptr(id),
+				d.ptr(id),
+				e.ptr(id),
+				f.ptr(id),
+				g.ptr(id),
+				h.ptr(id),
+				i.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package ecs
+
+import "iter"
+
+// Iterate over all entities that have component A, like [Query],
+// yielding a pointer to the component to read it.
+//
+// unlike Query this does not record changes, so systems that only use QueryRead
+// can run at the same time. writes through the pointer are not seen by [Changed]
+func QueryRead[A any](p *Pool) iter.Seq2[Entity, *A] {
+	a := GetStorage[A](p)
+	return func(yield func(Entity, *A) bool) {
+		for e := range a.IterAll() {
+			if !yield(e, a.components.ptr(e.Index())) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query2],
+// yielding pointers to the components to read them.
+//
+// unlike Query2 this does not record changes, so systems that only use QueryRead2
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead2[A any, B any](p *Pool) iter.Seq2[Entity, Row2[A, B]] {
+	a, b := GetStorage2[A, B](p)
+	return func(yield func(Entity, Row2[A, B]) bool) {
+		for ent := range a.IterAnd(b) {
+			id := ent.Index()
+			row := Row2[A, B]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query3],
+// yielding pointers to the components to read them.
+//
+// unlike Query3 this does not record changes, so systems that only use QueryRead3
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead3[A any, B any, C any](p *Pool) iter.Seq2[Entity, Row3[A, B, C]] {
+	a, b, c := GetStorage3[A, B, C](p)
+	return func(yield func(Entity, Row3[A, B, C]) bool) {
+		for ent := range a.IterAnd(b, c) {
+			id := ent.Index()
+			row := Row3[A, B, C]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+				c.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query4],
+// yielding pointers to the components to read them.
+//
+// unlike Query4 this does not record changes, so systems that only use QueryRead4
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead4[A any, B any, C any, D any](p *Pool) iter.Seq2[Entity, Row4[A, B, C, D]] {
+	a, b, c, d := GetStorage4[A, B, C, D](p)
+	return func(yield func(Entity, Row4[A, B, C, D]) bool) {
+		for ent := range a.IterAnd(b, c, d) {
+			id := ent.Index()
+			row := Row4[A, B, C, D]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+				c.components.ptr(id),
+				d.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query5],
+// yielding pointers to the components to read them.
+//
+// unlike Query5 this does not record changes, so systems that only use QueryRead5
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead5[A any, B any, C any, D any, E any](p *Pool) iter.Seq2[Entity, Row5[A, B, C, D, E]] {
+	a, b, c, d, e := GetStorage5[A, B, C, D, E](p)
+	return func(yield func(Entity, Row5[A, B, C, D, E]) bool) {
+		for ent := range a.IterAnd(b, c, d, e) {
+			id := ent.Index()
+			row := Row5[A, B, C, D, E]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+				c.components.ptr(id),
+				d.components.ptr(id),
+				e.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query6],
+// yielding pointers to the components to read them.
+//
+// unlike Query6 this does not record changes, so systems that only use QueryRead6
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead6[A any, B any, C any, D any, E any, F any](p *Pool) iter.Seq2[Entity, Row6[A, B, C, D, E, F]] {
+	a, b, c, d, e, f := GetStorage6[A, B, C, D, E, F](p)
+	return func(yield func(Entity, Row6[A, B, C, D, E, F]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f) {
+			id := ent.Index()
+			row := Row6[A, B, C, D, E, F]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+				c.components.ptr(id),
+				d.components.ptr(id),
+				e.components.ptr(id),
+				f.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query7],
+// yielding pointers to the components to read them.
+//
+// unlike Query7 this does not record changes, so systems that only use QueryRead7
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead7[A any, B any, C any, D any, E any, F any, G any](p *Pool) iter.Seq2[Entity, Row7[A, B, C, D, E, F, G]] {
+	a, b, c, d, e, f, g := GetStorage7[A, B, C, D, E, F, G](p)
+	return func(yield func(Entity, Row7[A, B, C, D, E, F, G]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f, g) {
+			id := ent.Index()
+			row := Row7[A, B, C, D, E, F, G]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+				c.components.ptr(id),
+				d.components.ptr(id),
+				e.components.ptr(id),
+				f.components.ptr(id),
+				g.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query8],
+// yielding pointers to the components to read them.
+//
+// unlike Query8 this does not record changes, so systems that only use QueryRead8
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead8[A any, B any, C any, D any, E any, F any, G any, H any](p *Pool) iter.Seq2[Entity, Row8[A, B, C, D, E, F, G, H]] {
+	a, b, c, d, e, f, g, h := GetStorage8[A, B, C, D, E, F, G, H](p)
+	return func(yield func(Entity, Row8[A, B, C, D, E, F, G, H]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f, g, h) {
+			id := ent.Index()
+			row := Row8[A, B, C, D, E, F, G, H]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+				c.components.ptr(id),
+				d.components.ptr(id),
+				e.components.ptr(id),
+				f.components.ptr(id),
+				g.components.ptr(id),
+				h.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all entities that have every one of the components, like [Query9],
+// yielding pointers to the components to read them.
+//
+// unlike Query9 this does not record changes, so systems that only use QueryRead9
+// can run at the same time. writes through the pointers are not seen by [Changed]
+func QueryRead9[A any, B any, C any, D any, E any, F any, G any, H any, I any](p *Pool) iter.Seq2[Entity, Row9[A, B, C, D, E, F, G, H, I]] {
+	a, b, c, d, e, f, g, h, i := GetStorage9[A, B, C, D, E, F, G, H, I](p)
+	return func(yield func(Entity, Row9[A, B, C, D, E, F, G, H, I]) bool) {
+		for ent := range a.IterAnd(b, c, d, e, f, g, h, i) {
+			id := ent.Index()
+			row := Row9[A, B, C, D, E, F, G, H, I]{
+				a.components.ptr(id),
+				b.components.ptr(id),
+				c.components.ptr(id),
+				d.components.ptr(id),
+				e.components.ptr(id),
+				f.components.ptr(id),
+				g.components.ptr(id),
+				h.components.ptr(id),
+				i.components.ptr(id),
+			}
+			if !yield(ent, row) {
+				return
+			}
+		}
+	}
+}
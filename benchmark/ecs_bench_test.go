@@ -6,21 +6,55 @@ import (
 	ecs "github.com/BrownNPC/simple-ecs"
 )
 
-func BenchmarkQuery(b *testing.B) {
-	type Vec2 struct {
-		X, Y float64
-	}
-	type Position Vec2
-	type Velocity Vec2
+type Vec2 struct {
+	X, Y float64
+}
+type Position Vec2
+type Velocity Vec2
 
+func newBenchPool() *ecs.Pool {
 	p := ecs.New(uint32(50_000))
 	for range 50_000 {
 		e := ecs.NewEntity(p)
 		ecs.Add2(p, e, Position{}, Velocity{1, 1})
 	}
-	// POSITION := ecs.GetStorage[Position](p)
-	// VELOCITY := ecs.GetStorage[Velocity](p)
-	for b.Loop() {
-		// POSITION.All()
+	return p
+}
+
+func BenchmarkQuery(b *testing.B) {
+	p := newBenchPool()
+	POSITION, VELOCITY := ecs.GetStorage2[Position, Velocity](p)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, e := range POSITION.And(VELOCITY) {
+			_ = e
+		}
+	}
+}
+
+func BenchmarkIterQuery(b *testing.B) {
+	p := newBenchPool()
+	POSITION, VELOCITY := ecs.GetStorage2[Position, Velocity](p)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for e := range POSITION.IterAnd(VELOCITY) {
+			_ = e
+		}
+	}
+}
+
+func BenchmarkForEachParallel(b *testing.B) {
+	p := newBenchPool()
+	POSITION, VELOCITY := ecs.GetStorage2[Position, Velocity](p)
+	query := ecs.NewFilter(p).With(POSITION, VELOCITY)
+	b.ResetTimer()
+	for range b.N {
+		ecs.ForEachParallel(query, 0, func(e ecs.Entity) {
+			pos, vel := POSITION.GetPtr(e), VELOCITY.GetPtr(e)
+			pos.X += vel.X
+			pos.Y += vel.Y
+		})
 	}
 }
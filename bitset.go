@@ -5,49 +5,71 @@ import (
 	"sync"
 )
 
-type bitSet struct {
+// A BitSet is a growable set of entity indexes, used by storages
+// to track which entities have their component
+type BitSet struct {
 	bits []uint64
 }
 
-func newBitset(size uint32) *bitSet {
-	return &bitSet{
+// Create a bitset with room for size bits
+func NewBitSet(size uint32) *BitSet {
+	return &BitSet{
 		bits: make([]uint64, (size+63)/64),
 	}
 }
 
-func (b *bitSet) Set(i uint32) {
+// make room for at least size bits, keeping the bits already set
+func (b *BitSet) Grow(size uint32) {
+	words := int((size + 63) / 64)
+	if words <= len(b.bits) {
+		return
+	}
+	grown := make([]uint64, words)
+	copy(grown, b.bits)
+	b.bits = grown
+}
+
+func (b *BitSet) Set(i uint32) {
 	word := i / 64
 	bit := i % 64
 	b.bits[word] |= 1 << bit
 }
 
-func (b *bitSet) Clear(i uint32) {
+func (b *BitSet) Clear(i uint32) {
 	word := i / 64
 	bit := i % 64
 	b.bits[word] &^= 1 << bit
 }
 
-func (b *bitSet) Get(i uint32) bool {
+func (b *BitSet) Get(i uint32) bool {
 	word := i / 64
 	bit := i % 64
 	return b.bits[word]&(1<<bit) != 0
 }
 
-func (b *bitSet) And(other *bitSet) {
+// the i-th word of the bitset, or 0 if it is out of range
+func (b *BitSet) word(i int) uint64 {
+	if i < len(b.bits) {
+		return b.bits[i]
+	}
+	return 0
+}
+
+func (b *BitSet) And(other *BitSet) {
 	n := min(len(b.bits), len(other.bits))
 	for i := range n {
 		b.bits[i] &= other.bits[i]
 	}
 }
 
-func (b *bitSet) Or(other *bitSet) {
+func (b *BitSet) Or(other *BitSet) {
 	n := min(len(b.bits), len(other.bits))
 	for i := range n {
 		b.bits[i] |= other.bits[i]
 	}
 }
 
-func (b *bitSet) AndNot(other *bitSet) {
+func (b *BitSet) AndNot(other *BitSet) {
 	n := min(len(b.bits), len(other.bits))
 	for i := range n {
 		b.bits[i] &^= other.bits[i]
@@ -56,12 +78,12 @@ func (b *bitSet) AndNot(other *bitSet) {
 
 var bitSetPool = sync.Pool{
 	New: func() any {
-		return &bitSet{}
+		return &BitSet{}
 	},
 }
 
-func (b *bitSet) Clone() *bitSet {
-	cloned := bitSetPool.Get().(*bitSet)
+func (b *BitSet) Clone() *BitSet {
+	cloned := bitSetPool.Get().(*BitSet)
 
 	if cap(cloned.bits) >= len(b.bits) {
 		cloned.bits = cloned.bits[:len(b.bits)]
@@ -73,17 +95,22 @@ func (b *bitSet) Clone() *bitSet {
 	return cloned
 }
 
-func (b *bitSet) Release() {
+func (b *BitSet) Release() {
 	clear(b.bits) // Only clear the used part
 	bitSetPool.Put(b)
 }
-func (b *bitSet) ActiveIDs() []uint32 {
+
+// number of set bits
+func (b *BitSet) Count() int {
 	total := 0
 	for _, w := range b.bits {
 		total += bits.OnesCount64(w)
 	}
+	return total
+}
 
-	ids := make([]uint32, 0, total)
+func (b *BitSet) ActiveIDs() []uint32 {
+	ids := make([]uint32, 0, b.Count())
 	for wi, w := range b.bits {
 		base := uint32(wi) * 64
 		for w != 0 {
@@ -6,7 +6,7 @@ import (
 )
 
 func TestSetGetClear(t *testing.T) {
-	b := newBitset(128)
+	b := NewBitSet(128)
 
 	b.Set(42)
 	if !b.Get(42) {
@@ -21,8 +21,8 @@ func TestSetGetClear(t *testing.T) {
 
 
 func TestAnd(t *testing.T) {
-	a := newBitset(128)
-	b := newBitset(128)
+	a := NewBitSet(128)
+	b := NewBitSet(128)
 
 	a.Set(10)
 	a.Set(20)
@@ -43,8 +43,8 @@ func TestAnd(t *testing.T) {
 }
 
 func TestOr(t *testing.T) {
-	a := newBitset(128)
-	b := newBitset(128)
+	a := NewBitSet(128)
+	b := NewBitSet(128)
 
 	a.Set(5)
 	b.Set(6)
@@ -57,8 +57,8 @@ func TestOr(t *testing.T) {
 }
 
 func TestAndNot(t *testing.T) {
-	a := newBitset(128)
-	b := newBitset(128)
+	a := NewBitSet(128)
+	b := NewBitSet(128)
 
 	a.Set(5)
 	a.Set(6)
@@ -75,7 +75,7 @@ func TestAndNot(t *testing.T) {
 }
 
 func TestActiveIDs(t *testing.T) {
-	b := newBitset(128)
+	b := NewBitSet(128)
 	expected := []uint32{3, 5, 64, 127}
 
 	for _, i := range expected {
@@ -0,0 +1,35 @@
+package ecs
+
+// A CachedQuery remembers the entities matched by a [Filter],
+// and only recomputes them when an entity is created or killed,
+// or when one of the storages in the filter gains or loses an entity.
+//
+// Use it for queries that run every frame.
+type CachedQuery struct {
+	filter   *Filter
+	version  uint64
+	computed bool
+	entities []Entity
+}
+
+// Create a cached query for the entities matched by f.
+//
+// f should not be changed after this
+func NewCachedQuery(f *Filter) *CachedQuery {
+	return &CachedQuery{filter: f}
+}
+
+// All entities that match the filter.
+//
+// The slice is reused by the query, so do not modify or keep it around
+func (q *CachedQuery) Entities() []Entity {
+	if v := q.filter.version(); !q.computed || v != q.version {
+		q.entities = q.entities[:0]
+		for e := range q.filter.Iter() {
+			q.entities = append(q.entities, e)
+		}
+		q.version = v
+		q.computed = true
+	}
+	return q.entities
+}
@@ -0,0 +1,86 @@
+package ecs
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+)
+
+// returned by [TryNewEntity] when the pool has no room for another entity
+var ErrPoolFull = errors.New("ecs: pool is full")
+
+// What the pool does when [NewEntity] is called and every slot is taken
+type CapacityPolicy uint8
+
+const (
+	// grow the pool and every storage (default)
+	PolicyGrow CapacityPolicy = iota
+	// NewEntity panics with ErrPoolFull
+	PolicyPanic
+	// NewEntity returns entity 0, which is never alive.
+	// use TryNewEntity to get ErrPoolFull
+	PolicyError
+	// kill the entity that was created the longest time ago and reuse its slot
+	PolicyEvictOldest
+)
+
+// Set what the pool does when it runs out of room for entities.
+//
+// The capacity passed to [New] is the limit for every policy except [PolicyGrow]
+func SetCapacityPolicy(p *Pool, policy CapacityPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case policy == PolicyEvictOldest && p.policy != PolicyEvictOldest:
+		p.spawnQueue = p.spawnedEntities()
+	case policy != PolicyEvictOldest:
+		p.spawnQueue = nil
+	}
+	p.policy = policy
+}
+
+// true if there are no dead entities to recycle and no unused slots.
+// must be called with the lock held
+func (p *Pool) full() bool {
+	return len(p.reusableIDs) == 0 && p.TotalEntities+1 >= p.capacity
+}
+
+// the alive entity that was created the longest time ago, or 0 if there is none.
+// dead entities at the front of the spawn queue are dropped on the way.
+// must be called with the lock held
+func (p *Pool) oldest() Entity {
+	for len(p.spawnQueue) > 0 {
+		e := p.spawnQueue[0]
+		if IsAlive(p, e) {
+			return e
+		}
+		p.spawnQueue = p.spawnQueue[1:]
+	}
+	return 0
+}
+
+// add a spawned entity to the back of the spawn queue,
+// dropping the dead entities once they take up most of the queue.
+// must be called with the lock held
+func (p *Pool) queueSpawn(e Entity) {
+	if len(p.spawnQueue) >= 2*int(p.capacity) {
+		p.spawnQueue = slices.DeleteFunc(p.spawnQueue, func(e Entity) bool { return !IsAlive(p, e) })
+	}
+	p.spawnQueue = append(p.spawnQueue, e)
+}
+
+// the alive entities in the order they spawned.
+// must be called with the lock held
+func (p *Pool) spawnedEntities() []Entity {
+	entities := p.entities(p.entityActiveStatus)
+	slices.SortFunc(entities, func(a, b Entity) int {
+		return cmp.Compare(p.spawnOrder[a.Index()], p.spawnOrder[b.Index()])
+	})
+	return entities
+}
+
+// double the capacity, without going past the largest index
+func nextCapacity(capacity uint32) uint32 {
+	return uint32(min(uint64(capacity)*2, math.MaxUint32))
+}
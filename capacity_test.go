@@ -0,0 +1,102 @@
+package ecs
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Test TryNewEntity and NewEntity with each capacity policy
+func TestCapacityPolicies(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		p := New(2)
+		SetCapacityPolicy(p, PolicyError)
+		NewEntity(p)
+		NewEntity(p)
+		if _, err := TryNewEntity(p); !errors.Is(err, ErrPoolFull) {
+			t.Errorf("expected ErrPoolFull, got %v", err)
+		}
+		if e := NewEntity(p); e != 0 || IsAlive(p, e) {
+			t.Errorf("expected dead entity 0 from a full pool, got %d", e)
+		}
+	})
+	t.Run("panic", func(t *testing.T) {
+		p := New(1)
+		SetCapacityPolicy(p, PolicyPanic)
+		NewEntity(p)
+		defer func() {
+			if r := recover(); r != ErrPoolFull {
+				t.Errorf("expected panic with ErrPoolFull, got %v", r)
+			}
+		}()
+		NewEntity(p)
+	})
+	t.Run("grow", func(t *testing.T) {
+		p := New(1)
+		for range 10 {
+			if _, err := TryNewEntity(p); err != nil {
+				t.Fatalf("growing pool returned error %v", err)
+			}
+		}
+	})
+	t.Run("evict oldest", func(t *testing.T) {
+		type Comp struct{ Value int }
+		p := New(3)
+		SetCapacityPolicy(p, PolicyEvictOldest)
+		e1 := NewEntity(p)
+		e2 := NewEntity(p)
+		e3 := NewEntity(p)
+		Add(p, e1, Comp{Value: 1})
+		// recycling e1 makes e2 the oldest
+		Kill(p, e1)
+		e1 = NewEntity(p)
+		e4 := NewEntity(p)
+		if IsAlive(p, e2) {
+			t.Errorf("expected oldest entity %d to be evicted", e2)
+		}
+		if e4.Index() != e2.Index() {
+			t.Errorf("expected evicted slot %d to be reused, got %d", e2.Index(), e4.Index())
+		}
+		for _, e := range []Entity{e1, e3, e4} {
+			if !IsAlive(p, e) {
+				t.Errorf("entity %d should still be alive", e)
+			}
+		}
+	})
+}
+
+// Test that evicting keeps the spawn order when the policy is set late,
+// and that the spawn queue does not grow without bound
+func TestEvictOldestQueue(t *testing.T) {
+	p := New(4)
+	es := []Entity{NewEntity(p), NewEntity(p), NewEntity(p), NewEntity(p)}
+	Kill(p, es[0])
+	es[0] = NewEntity(p) // now the newest
+	SetCapacityPolicy(p, PolicyEvictOldest)
+	for i, want := range []Entity{es[1], es[2], es[3], es[0]} {
+		NewEntity(p)
+		if IsAlive(p, want) {
+			t.Errorf("eviction %d: expected %d to be evicted", i, want)
+		}
+	}
+	for range 100 {
+		e := NewEntity(p)
+		Kill(p, e)
+	}
+	if n := len(p.spawnQueue); n > 2*int(p.capacity)+1 {
+		t.Errorf("expected the spawn queue to be compacted, got %d entities", n)
+	}
+}
+
+// Test that the capacity never wraps around
+func TestNextCapacity(t *testing.T) {
+	for capacity, want := range map[uint32]uint32{
+		10:             20,
+		1 << 31:        math.MaxUint32,
+		math.MaxUint32: math.MaxUint32,
+	} {
+		if got := nextCapacity(capacity); got != want {
+			t.Errorf("nextCapacity(%d): expected %d, got %d", capacity, want, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ecs
+
+// The world tick of a pool. Every change to a component records the current tick,
+// and [AdvanceTick] moves the world to the next tick.
+type Tick = uint64
+
+// The current world tick of the pool
+func CurrentTick(p *Pool) Tick {
+	return p.tick.Load()
+}
+
+// End the current world tick and return it.
+//
+// Changes made before this call are recorded with a tick <= the returned tick,
+// and changes made after it with a later tick. Call it at the end of a system
+// and store the result, to find what changed when the system runs again:
+//
+//	var lastRun ecs.Tick
+//	func networkSystem(p *ecs.Pool, dt float64) {
+//		for e := range ecs.NewFilter(p).With(ecs.Changed[Position](p, lastRun)).Iter() {
+//			...
+//		}
+//		lastRun = ecs.AdvanceTick(p)
+//	}
+func AdvanceTick(p *Pool) Tick {
+	return p.tick.Add(1) - 1
+}
+
+// Match entities that gained Component after the since tick.
+//
+// Use it as a term of a [Filter].
+// pass 0 as since to match every entity that has the component
+func Added[Component any](p *Pool, since Tick) changeFilter[Component] {
+	st := GetStorage[Component](p)
+	st.trackChanges()
+	return changeFilter[Component]{st: st, since: since, added: true}
+}
+
+// Match entities that gained or changed Component after the since tick.
+//
+// Use it as a term of a [Filter].
+// Changes are recorded by [Add], [Storage.Update], [Storage.MarkChanged],
+// and by getting a pointer to the component with [Storage.GetPtr], [Storage.TryGetPtr] or [Query].
+// [QueryRead] does not record changes.
+// pass 0 as since to match every entity that has the component
+func Changed[Component any](p *Pool, since Tick) changeFilter[Component] {
+	st := GetStorage[Component](p)
+	st.trackChanges()
+	return changeFilter[Component]{st: st, since: since}
+}
+
+// filter term returned by Added and Changed
+type changeFilter[Component any] struct {
+	st    *Storage[Component]
+	since Tick
+	added bool
+}
+
+func (f changeFilter[Component]) word(i int) uint64 {
+	w := f.st.b.word(i)
+	ticks := f.st.changed
+	if f.added {
+		ticks = f.st.added
+	}
+	var matched uint64
+	for bit := range 64 {
+		if w&(1<<bit) != 0 && ticks[i*64+bit] > f.since {
+			matched |= 1 << bit
+		}
+	}
+	return matched
+}
+
+// changes do not bump the storage version, so always look different to cached queries
+func (f changeFilter[Component]) version() uint64 { return f.st.pool.volatile.Add(1) }
+
+func (f changeFilter[Component]) owner() *Pool { return f.st.pool }
+
+// check if the component of an entity was added or changed since the tick
+func (f changeFilter[Component]) Has(e Entity) bool { return matches(f, e) }
+
+// start recording change ticks.
+// entities that already have the component count as added and changed now
+func (s *Storage[Component]) trackChanges() {
+	p := s.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s.changed != nil {
+		return
+	}
+	s.added = make([]Tick, p.capacity)
+	s.changed = make([]Tick, p.capacity)
+	now := p.tick.Load()
+	for _, id := range s.b.ActiveIDs() {
+		s.added[id] = now
+		s.changed[id] = now
+	}
+}
+
+func (s *Storage[Component]) markAdded(id uint32) {
+	if s.changed != nil {
+		now := s.pool.tick.Load()
+		s.added[id] = now
+		s.changed[id] = now
+	}
+}
+
+// Record that the component of an entity was changed, for [Changed].
+//
+// use it after writing to a component through a pointer from [QueryRead]
+func (s *Storage[Component]) MarkChanged(e Entity) {
+	if IsAlive(s.pool, e) {
+		s.markChanged(e.Index())
+	}
+}
+
+func (s *Storage[Component]) markChanged(id uint32) {
+	if s.changed != nil {
+		s.changed[id] = s.pool.tick.Load()
+	}
+}
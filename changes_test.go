@@ -0,0 +1,61 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that Added and Changed only match entities touched since a tick
+func TestChangeDetection(t *testing.T) {
+	type Position struct{ X int }
+	p := New(10)
+	POSITION := GetStorage[Position](p)
+	old := NewEntity(p)
+	Add(p, old, Position{})
+
+	// the first run sees everything
+	var lastRun Tick
+	added := func() []Entity {
+		return NewFilter(p).With(Added[Position](p, lastRun)).Entities()
+	}
+	changed := func() []Entity {
+		return NewFilter(p).With(Changed[Position](p, lastRun)).Entities()
+	}
+	if got := changed(); !slices.Equal(got, []Entity{old}) {
+		t.Errorf("expected first run to see [%d], got %v", old, got)
+	}
+	lastRun = AdvanceTick(p)
+	if got := changed(); len(got) != 0 {
+		t.Errorf("expected no changes, got %v", got)
+	}
+
+	updated, pointed, fresh := NewEntity(p), NewEntity(p), NewEntity(p)
+	Add(p, updated, Position{})
+	Add(p, pointed, Position{})
+	lastRun = AdvanceTick(p)
+
+	POSITION.Update(updated, Position{X: 1})
+	POSITION.GetPtr(pointed).X = 2
+	Add(p, fresh, Position{})
+	if got := changed(); !slices.Equal(got, []Entity{updated, pointed, fresh}) {
+		t.Errorf("expected changed [%d %d %d], got %v", updated, pointed, fresh, got)
+	}
+	if got := added(); !slices.Equal(got, []Entity{fresh}) {
+		t.Errorf("expected added [%d], got %v", fresh, got)
+	}
+
+	// cached queries with change filters always recompute
+	q := NewCachedQuery(NewFilter(p).With(Changed[Position](p, lastRun)))
+	q.Entities()
+	lastRun = AdvanceTick(p)
+	for range QueryRead[Position](p) {
+	}
+	if got := changed(); len(got) != 0 {
+		t.Errorf("expected QueryRead to not record changes, got %v", got)
+	}
+	for range Query[Position](p) {
+	}
+	if n := len(q.Entities()); n != 4 {
+		t.Errorf("expected cached query to see 4 changes, got %d", n)
+	}
+}
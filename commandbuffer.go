@@ -0,0 +1,93 @@
+package ecs
+
+import "sync"
+
+// A CommandBuffer records structural changes (creating and killing entities,
+// adding and removing components) and applies them to the pool when [CommandBuffer.Flush] is called.
+//
+// Use it to make changes while looping over a query,
+// and flush it once the loop is done.
+// It is safe to record commands from multiple goroutines.
+type CommandBuffer struct {
+	pool     *Pool
+	mu       sync.Mutex
+	commands []func(p *Pool)
+}
+
+// Create a command buffer that applies its commands to p
+func NewCommandBuffer(p *Pool) *CommandBuffer {
+	return &CommandBuffer{pool: p}
+}
+
+func (cb *CommandBuffer) record(command func(p *Pool)) {
+	cb.mu.Lock()
+	cb.commands = append(cb.commands, command)
+	cb.mu.Unlock()
+}
+
+// Reserve an entity that will be created when the buffer is flushed.
+//
+// The entity can be passed to [DeferAdd] and other commands right away,
+// but it is not alive until the buffer is flushed.
+// Reserving never changes the pool, so it is safe to call while iterating.
+// If the pool is full, the [CapacityPolicy] of the pool is applied at flush:
+// the pool grows, the oldest entity is killed, the entity is never created
+// ([PolicyError]), or Flush panics with [ErrPoolFull] ([PolicyPanic]).
+//
+// the slots of entities reserved by a buffer that is never flushed are lost
+func (cb *CommandBuffer) NewEntity() Entity {
+	p := cb.pool
+	e, err := p.reserve(false)
+	if err != nil { // every index is taken
+		if p.policy == PolicyPanic {
+			panic(err)
+		}
+		return 0
+	}
+	cb.record(func(p *Pool) {
+		if err := p.spawnReserved(e); err != nil {
+			if p.policy == PolicyPanic {
+				panic(err)
+			}
+			return
+		}
+		p.emit(EntityCreated, e, nil)
+	})
+	return e
+}
+
+// Kill the entities when the buffer is flushed
+func (cb *CommandBuffer) Kill(entities ...Entity) {
+	entities = append([]Entity(nil), entities...)
+	cb.record(func(p *Pool) {
+		Kill(p, entities...)
+	})
+}
+
+// Add a component to an entity when the buffer is flushed
+func DeferAdd[Component any](cb *CommandBuffer, e Entity, c Component) {
+	cb.record(func(p *Pool) {
+		Add(p, e, c)
+	})
+}
+
+// Remove a component from an entity when the buffer is flushed
+func DeferRemove[Component any](cb *CommandBuffer, e Entity) {
+	cb.record(func(p *Pool) {
+		Remove[Component](p, e)
+	})
+}
+
+// Apply the recorded commands to the pool in the order they were recorded,
+// and empty the buffer.
+//
+// commands recorded while flushing are applied by the next flush
+func (cb *CommandBuffer) Flush() {
+	cb.mu.Lock()
+	commands := cb.commands
+	cb.commands = nil
+	cb.mu.Unlock()
+	for _, command := range commands {
+		command(cb.pool)
+	}
+}
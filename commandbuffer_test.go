@@ -0,0 +1,176 @@
+package ecs
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Test that commands are applied in order at flush
+func TestCommandBuffer(t *testing.T) {
+	type Position struct{ X int }
+	type Dead struct{}
+	p := New(4)
+	POSITION := GetStorage[Position](p)
+	for i := range 3 {
+		Add(p, NewEntity(p), Position{X: i})
+	}
+
+	cb := NewCommandBuffer(p)
+	var spawned Entity
+	for e := range POSITION.IterAll() {
+		if POSITION.Get(e).X == 1 {
+			cb.Kill(e)
+			spawned = cb.NewEntity()
+			DeferAdd(cb, spawned, Position{X: 10})
+			DeferAdd(cb, spawned, Dead{})
+			DeferRemove[Dead](cb, spawned)
+		}
+	}
+	if spawned == 0 {
+		t.Fatalf("expected an entity to be reserved")
+	}
+	if IsAlive(p, spawned) {
+		t.Errorf("reserved entity should not be alive before flush")
+	}
+	if n := len(POSITION.All()); n != 3 {
+		t.Errorf("expected no changes before flush, got %d entities", n)
+	}
+
+	cb.Flush()
+	if !IsAlive(p, spawned) {
+		t.Fatalf("reserved entity should be alive after flush")
+	}
+	var xs []int
+	for e := range POSITION.IterAll() {
+		xs = append(xs, POSITION.Get(e).X)
+	}
+	slices.Sort(xs)
+	if !slices.Equal(xs, []int{0, 2, 10}) {
+		t.Errorf("expected positions [0 2 10], got %v", xs)
+	}
+	if GetStorage[Dead](p).EntityHasComponent(spawned) {
+		t.Errorf("expected deferred remove to run after deferred add")
+	}
+
+	// flushing again does nothing
+	cb.Flush()
+	if n := len(POSITION.All()); n != 3 {
+		t.Errorf("expected 3 entities after second flush, got %d", n)
+	}
+}
+
+// Test that reserving entities in a full pool does not move the components
+// while iterating. run with -race
+func TestCommandBufferReserveWhileIterating(t *testing.T) {
+	type Position struct{ X int }
+	p := New(64)
+	POSITION := GetStorage[Position](p)
+	for range 64 {
+		Add(p, NewEntity(p), Position{})
+	}
+	cb := NewCommandBuffer(p)
+	ForEachParallel(POSITION, 4, func(e Entity) {
+		pos := POSITION.GetPtr(e)
+		spawned := cb.NewEntity()
+		DeferAdd(cb, spawned, Position{X: 2})
+		pos.X = 1 // the pointer must still point into the storage
+	})
+	if p.capacity != 65 {
+		t.Errorf("reserving should not grow the pool, capacity is %d", p.capacity)
+	}
+	cb.Flush()
+	var ones, twos int
+	for e := range POSITION.IterAll() {
+		switch POSITION.Get(e).X {
+		case 1:
+			ones++
+		case 2:
+			twos++
+		}
+	}
+	if ones != 64 || twos != 64 {
+		t.Errorf("expected 64 written and 64 spawned entities, got %d and %d", ones, twos)
+	}
+}
+
+// Test that reserving in a full pool applies the capacity policy at flush,
+// and never changes the pool before. run with -race
+func TestCommandBufferReservePolicies(t *testing.T) {
+	type Position struct{ X int }
+	fill := func(policy CapacityPolicy) (*Pool, []Entity) {
+		p := New(256)
+		SetCapacityPolicy(p, policy)
+		var es []Entity
+		for range 256 {
+			e := NewEntity(p)
+			Add(p, e, Position{})
+			es = append(es, e)
+		}
+		return p, es
+	}
+	alive := func(p *Pool) int { return p.entityActiveStatus.Count() }
+
+	t.Run("evict oldest", func(t *testing.T) {
+		p, es := fill(PolicyEvictOldest)
+		POSITION := GetStorage[Position](p)
+		cb := NewCommandBuffer(p)
+		var mu sync.Mutex
+		var reserved []Entity
+		ForEachParallel(POSITION, 4, func(e Entity) {
+			pos := POSITION.GetPtr(e)
+			r := cb.NewEntity()
+			mu.Lock()
+			reserved = append(reserved, r)
+			mu.Unlock()
+			pos.X = 1
+		})
+		if n := alive(p); n != 256 {
+			t.Fatalf("reserving should not kill entities, %d alive", n)
+		}
+		for _, e := range es {
+			if POSITION.Get(e).X != 1 {
+				t.Fatalf("expected every write to be kept")
+			}
+		}
+		cb.Flush()
+		if n := alive(p); n != 256 {
+			t.Errorf("expected the pool to stay at 256 entities, got %d", n)
+		}
+		for _, e := range reserved {
+			if !IsAlive(p, e) {
+				t.Fatalf("expected reserved entity %d to be alive", e)
+			}
+		}
+		for _, e := range es {
+			if IsAlive(p, e) {
+				t.Fatalf("expected the old entities to be evicted")
+			}
+		}
+		NewEntity(p)
+		if n := alive(p); n != 256 {
+			t.Errorf("expected the limit to hold after flushing, got %d entities", n)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		p, _ := fill(PolicyError)
+		cb := NewCommandBuffer(p)
+		e := cb.NewEntity()
+		DeferAdd(cb, e, Position{X: 1})
+		cb.Flush()
+		if IsAlive(p, e) || alive(p) != 256 {
+			t.Errorf("a full pool should not create the reserved entity")
+		}
+	})
+	t.Run("panic", func(t *testing.T) {
+		p, _ := fill(PolicyPanic)
+		cb := NewCommandBuffer(p)
+		cb.NewEntity()
+		defer func() {
+			if r := recover(); r != ErrPoolFull {
+				t.Errorf("expected Flush to panic with ErrPoolFull, got %v", r)
+			}
+		}()
+		cb.Flush()
+	})
+}
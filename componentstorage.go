@@ -0,0 +1,108 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ComponentStorage is implemented by every storage the pool knows about.
+//
+// [Storage] implements it, and so can your own storages,
+// eg. map-backed, paged or memory-mapped ones. Register them with [RegisterStorage].
+//
+// The pool only needs to know which entities have the component, through the bitset,
+// and how to remove the component when an entity is killed.
+// A storage that also has a Set(e Entity, c Component) method works with [Add].
+// Add calls Set before setting the bit of the entity, so Set can tell
+// a new component from an update.
+type ComponentStorage interface {
+	// which entities have the component, indexed by [Entity.Index].
+	// the pool grows the bitset when it grows
+	Bits() *BitSet
+	// remove the component of an entity. called by Kill after the entity is dead,
+	// so do not check if the entity is alive.
+	// [Remove] and [Kill] clear the bit of the entity afterwards
+	Clear(e Entity)
+	// check if an entity has the component
+	Has(e Entity) bool
+	// the type of component stored
+	Type() reflect.Type
+}
+
+// implemented by storages that want to know when the pool grows
+type growableStorage interface {
+	Grow(capacity uint32)
+}
+
+// implemented by storages of this package, they grow their own bitset
+type internalStorage interface {
+	grow(capacity uint32)
+}
+
+// storage that can be used with Add
+type componentSetter[Component any] interface {
+	Set(e Entity, c Component)
+}
+
+// add a component to a storage registered with RegisterStorage
+func addCustom[Component any](p *Pool, e Entity, c Component, st ComponentStorage) {
+	setter, ok := st.(componentSetter[Component])
+	if !ok {
+		panic(fmt.Sprintf("ecs: the storage for %v does not have a Set(Entity, %v) method", st.Type(), st.Type()))
+	}
+	id := e.Index()
+	added := !st.Bits().Get(id)
+	setter.Set(e, c)
+	st.Bits().Set(id)
+	if added {
+		p.emitComponent(ComponentAdded, e, st)
+	}
+}
+
+// Register your own storage for its component type.
+//
+// The storage is used by [Kill], [Add], [Remove] and queries like any other,
+// but [GetStorage] panics for its component type, use [GetComponentStorage] instead.
+// returns an error if the component type already has a storage
+func RegisterStorage(p *Pool, st ComponentStorage) error {
+	// same key as GetStorage: a nil pointer to the component type
+	key := reflect.Zero(reflect.PointerTo(st.Type())).Interface()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.storages[key]; ok {
+		return fmt.Errorf("ecs: component %v already has a storage", st.Type())
+	}
+	st.Bits().Grow(p.capacity)
+	switch st := st.(type) {
+	case internalStorage:
+		st.grow(p.capacity)
+	case growableStorage:
+		st.Grow(p.capacity)
+	}
+	p.storages[key] = st
+	p.allStorages = append(p.allStorages, st)
+	p.unmasked = append(p.unmasked, st)
+	return nil
+}
+
+// Get the storage registered for Component, whatever its kind.
+//
+// returns nil if Component has no storage yet
+func GetComponentStorage[Component any](p *Pool) ComponentStorage {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.storages[(*Component)(nil)]
+}
+
+// storage registered with RegisterStorage used as a filter term
+type customMatcher struct {
+	st   ComponentStorage
+	pool *Pool
+}
+
+func (m customMatcher) word(i int) uint64 { return m.st.Bits().word(i) }
+
+// custom storages do not have a version, so always look different to cached queries
+func (m customMatcher) version() uint64 { return m.pool.volatile.Add(1) }
+
+func (m customMatcher) owner() *Pool { return m.pool }
@@ -0,0 +1,128 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// storage that keeps its components in a map
+type mapStorage[Component any] struct {
+	b     *BitSet
+	m     map[Entity]Component
+	grown uint32
+}
+
+func newMapStorage[Component any]() *mapStorage[Component] {
+	return &mapStorage[Component]{b: NewBitSet(0), m: make(map[Entity]Component)}
+}
+
+func (s *mapStorage[Component]) Bits() *BitSet             { return s.b }
+func (s *mapStorage[Component]) Has(e Entity) bool         { _, ok := s.m[e]; return ok }
+func (s *mapStorage[Component]) Type() reflect.Type        { return reflect.TypeFor[Component]() }
+func (s *mapStorage[Component]) Set(e Entity, c Component) { s.m[e] = c }
+func (s *mapStorage[Component]) Grow(capacity uint32)      { s.grown = capacity }
+func (s *mapStorage[Component]) Clear(e Entity) {
+	s.b.Clear(e.Index())
+	delete(s.m, e)
+}
+
+func TestRegisterStorage(t *testing.T) {
+	type Name string
+	type Position struct{}
+	p := New(10)
+	NAME := newMapStorage[Name]()
+	if err := RegisterStorage(p, NAME); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterStorage(p, newMapStorage[Name]()); err == nil {
+		t.Errorf("registering a second storage for the same component should fail")
+	}
+	if GetComponentStorage[Name](p) != NAME {
+		t.Errorf("GetComponentStorage should return the registered storage")
+	}
+	if NAME.grown != p.capacity {
+		t.Errorf("expected the storage to grow to %d, got %d", p.capacity, NAME.grown)
+	}
+
+	var observed []LifecycleKind
+	Observe(p, func(_ *Pool, ev LifecycleEvent) {
+		if ev.Component == NAME.Type() {
+			observed = append(observed, ev.Kind)
+		}
+	})
+	POSITION := GetStorage[Position](p)
+	e1, e2, e3 := NewEntity(p), NewEntity(p), NewEntity(p)
+	Add(p, e1, Name("a"))
+	Add(p, e2, Name("b"))
+	Add(p, e2, Name("bb"))
+	Add(p, e1, Position{})
+	Add(p, e3, Position{})
+	if NAME.m[e2] != "bb" || !NAME.Has(e1) || NAME.Has(e3) {
+		t.Errorf("Add should set the components, got %v", NAME.m)
+	}
+
+	if got := POSITION.And(NAME); !slices.Equal(got, []Entity{e1}) {
+		t.Errorf("expected [%d], got %v", e1, got)
+	}
+	f := NewFilter(p).With(NAME).Without(POSITION)
+	if got := f.Entities(); !slices.Equal(got, []Entity{e2}) {
+		t.Errorf("expected [%d], got %v", e2, got)
+	}
+	if !f.Has(e2) || f.Has(e1) {
+		t.Errorf("Filter.Has does not match Filter.Entities")
+	}
+
+	Remove[Name](p, e2)
+	Kill(p, e1)
+	if len(NAME.m) != 0 || NAME.b.Count() != 0 {
+		t.Errorf("Remove and Kill should clear the storage, got %v", NAME.m)
+	}
+	want := []LifecycleKind{ComponentAdded, ComponentAdded, ComponentRemoved, ComponentRemoved}
+	if !slices.Equal(observed, want) {
+		t.Errorf("expected events %v, got %v", want, observed)
+	}
+
+	for range 20 {
+		NewEntity(p)
+	}
+	if NAME.grown != p.capacity || len(NAME.b.bits) != len(p.entityActiveStatus.bits) {
+		t.Errorf("the storage should grow with the pool")
+	}
+}
+
+func TestGetStorageCustomPanics(t *testing.T) {
+	type Name string
+	p := New(10)
+	RegisterStorage(p, newMapStorage[Name]())
+	defer func() {
+		if recover() == nil {
+			t.Errorf("GetStorage should panic for a custom storage")
+		}
+	}()
+	GetStorage[Name](p)
+}
+
+// storage that leaves its bitset to the pool
+type lazyStorage struct{ *mapStorage[string] }
+
+func (s lazyStorage) Clear(e Entity) { delete(s.m, e) }
+
+// Test that the pool clears the bit of custom storages on Remove and Kill
+func TestCustomStorageBits(t *testing.T) {
+	p := New(4)
+	st := lazyStorage{newMapStorage[string]()}
+	RegisterStorage(p, st)
+	e1, e2 := NewEntity(p), NewEntity(p)
+	Add(p, e1, "a")
+	Add(p, e2, "b")
+	Remove[string](p, e1)
+	Kill(p, e2)
+	if n := st.b.Count(); n != 0 {
+		t.Errorf("expected no bits after Remove and Kill, got %d", n)
+	}
+	recycled := NewEntity(p)
+	if NewFilter(p).With(st).Has(recycled) {
+		t.Errorf("a recycled entity should not have the component")
+	}
+}
@@ -7,11 +7,15 @@ entity component system that is performant an easy to use.
 
 	We use a struct called storage to hold the components arrays
 	 components can be any data type, but they cannot be interfaces
-	These arrays are pre-allocated to a fixed size provided by the user
+	These arrays are pre-allocated to a size provided by the user,
+	and grow when more entities are created
 
 	an entity is just an index into these arrays
 	So on the X axis there are entities which are just indexes
 
+	entities also carry the generation of their slot,
+	so a handle to a killed entity is rejected even if its index is reused
+
 	The storage struct also has a bitset.
 
 	each bit in the bitset corresponds to an entity
@@ -1,21 +1,49 @@
 package ecs
 
 import (
+	"fmt"
+	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
 )
 
-// An entity is just an integer.
-// But if you are storing entities within components, then do not forget to also
-// store their generation, and verify them using [IsAliveWithGeneration] before looping
-// over said components
-type Entity = uint32
+// An entity is a handle to a slot in the pool.
+//
+// The low 32 bits are the index of the slot, and the high 32 bits are
+// the generation of the slot when the handle was created.
+// Killing an entity increments the generation of its slot, so old handles
+// are rejected by every function that takes an Entity, even after the slot is recycled.
+// This means it is safe to store entities within components.
+type Entity uint64
 type Generation = uint32
 
-// A storage holds a slice of components
+func newEntity(index uint32, generation Generation) Entity {
+	return Entity(generation)<<32 | Entity(index)
+}
+
+// Index of the entity in the component storages
+func (e Entity) Index() uint32 { return uint32(e) }
+
+// Generation of the slot when this handle was created
+func (e Entity) Generation() Generation { return Generation(e >> 32) }
+
+// A storage holds the components of a type, and a bitset of which entities have them
 type Storage[Component any] struct {
-	ID         int
-	components []Component
-	b          *bitSet
+	ID         int                      // index in the pool, and bit in the component mask of entities
+	kind       StorageKind              // how the components are kept
+	components componentData[Component] // depends on the kind
+	b          *BitSet
+	pool       *Pool  // used to validate entity generations
+	modified   uint64 // bumped when an entity gains or loses this component, used by cached queries
+	internal   bool   // keeps data for the pool itself, like the hierarchy, so observers do not hear about it
+
+	added   []Tick // when each entity gained this component, nil until changes are tracked
+	changed []Tick // when each entity last changed this component, nil until changes are tracked
+
+	onAdd    []Hook[Component]
+	onSet    []Hook[Component]
+	onRemove []Hook[Component]
 	//[]Entity from Pool
 	// used for queries
 	parentPoolEntities *sync.Pool
@@ -27,24 +55,56 @@ type Pool struct {
 	capacity      uint32
 
 	mu          sync.RWMutex
-	storages    map[any]storage // mapped from nilptr of Component to Storage[Component]
-	allStorages []storage       // used for quickly killing entities, faster than iterating a map
+	storages    map[any]ComponentStorage // mapped from nilptr of Component to Storage[Component]
+	allStorages []ComponentStorage       // indexed by Storage.ID
+	unmasked    []ComponentStorage       // storages without a bit in the masks, checked one by one by Kill
+	resources   map[any]any              // mapped from nilptr of T to *T
+	eventQueues []eventQueue             // every Events[T], swapped by UpdateEvents
+
+	observed  atomic.Bool // true once an observer is registered, checked before locking observeMu
+	observeMu sync.Mutex
+	observers []Observer
+	buffering bool             // hold lifecycle events until FlushLifecycleEvents
+	pending   []LifecycleEvent // held lifecycle events
+
+	hierarchyOnce sync.Once        // registers the hooks used by the hierarchy
+	onKill        []func(e Entity) // called after an entity is killed and its components are cleared
 
 	reusableIDs        []uint32
 	generations        []Generation // incremented after every entity is killed. Used to prevent errors when we reuse an entity that the user was storing
-	entityActiveStatus *bitSet      // track which entities are alive= w
+	entityActiveStatus *BitSet      // track which entities are alive= w
+	masks              []uint64     // the components of each entity, maskWords per entity, one bit per Storage.ID
+	maskWords          uint32
+
+	modified   uint64         // bumped when an entity is created or killed, used by cached queries
+	policy     CapacityPolicy // what to do when the pool is full
+	spawnOrder []uint64       // when each slot was last handed out, used to find the oldest entity
+	spawned    uint64         // total entities handed out
+	spawnQueue []Entity       // entities in the order they spawned, only kept with PolicyEvictOldest. may hold dead entities
+
+	tick     atomic.Uint64 // the world tick, recorded when components change
+	volatile atomic.Uint64 // used to make cached queries with change filters always recompute
 
 	// passed to storages
 	poolEntititySlices sync.Pool // pool of []Entity, used for queries
 }
 
+// Create a pool with room for capacity entities.
+//
+// The pool grows automatically when more entities are created,
+// so capacity is only the initial size.
 func New(capacity uint32) (p *Pool) {
 	capacity++ //index 0 is unused so we should allocated 1 extra entity
 	p = &Pool{capacity: capacity}
-	p.entityActiveStatus = newBitset(capacity)
-	p.storages = make(map[any]storage)
-	p.reusableIDs = make([]Entity, 0, capacity)
+	p.entityActiveStatus = NewBitSet(capacity)
+	p.storages = make(map[any]ComponentStorage)
+	p.resources = make(map[any]any)
+	p.reusableIDs = make([]uint32, 0, capacity)
 	p.generations = make([]Generation, capacity)
+	p.spawnOrder = make([]uint64, capacity)
+	p.maskWords = 1
+	p.masks = make([]uint64, capacity)
+	p.tick.Store(1) // so changes are newer than a system that never ran
 	p.poolEntititySlices = sync.Pool{
 		New: func() any {
 			return make([]Entity, p.capacity)
@@ -54,76 +114,224 @@ func New(capacity uint32) (p *Pool) {
 }
 
 // recycle a dead entity id, or create a new one
+//
+// when the pool is full, the behaviour depends on the [CapacityPolicy] of the pool.
+// with [PolicyError] the returned entity is 0, which is never alive.
 func NewEntity(p *Pool) Entity {
+	e, err := TryNewEntity(p)
+	if err != nil && p.policy == PolicyPanic {
+		panic(err)
+	}
+	return e
+}
+
+// recycle a dead entity id, or create a new one.
+//
+// returns [ErrPoolFull] if the pool is full and
+// the [CapacityPolicy] of the pool is [PolicyPanic] or [PolicyError]
+func TryNewEntity(p *Pool) (Entity, error) {
+	e, err := p.reserve(true)
+	if err == nil {
+		p.emit(EntityCreated, e, nil)
+	}
+	return e, err
+}
+
+// take a slot for a new entity, and bring it to life if spawn is true.
+// entities that are not spawned yet are not alive until [Pool.spawn] is called
+func (p *Pool) reserve(spawn bool) (Entity, error) {
 	p.mu.Lock()
+	// entities reserved for later get a slot past the capacity when the pool is full,
+	// and the capacity policy is applied when they spawn, see [Pool.spawnReserved].
+	// so reserving never kills entities or moves the components while the caller is iterating
+	reserveLater := !spawn && p.TotalEntities+1 < math.MaxUint32
+	for !reserveLater && p.full() {
+		switch p.policy {
+		case PolicyGrow:
+			if p.capacity == math.MaxUint32 { // every index is taken
+				p.mu.Unlock()
+				return 0, ErrPoolFull
+			}
+			p.grow(nextCapacity(p.capacity))
+		case PolicyEvictOldest:
+			oldest := p.oldest()
+			if oldest == 0 { // nothing to evict
+				p.mu.Unlock()
+				return 0, ErrPoolFull
+			}
+			p.mu.Unlock()
+			Kill(p, oldest)
+			p.mu.Lock()
+		default:
+			p.mu.Unlock()
+			return 0, ErrPoolFull
+		}
+	}
 	defer p.mu.Unlock()
-	reusableLen := len(p.reusableIDs)
-	if reusableLen > 0 { // reuse
-		id := p.reusableIDs[reusableLen-1]
+	var id uint32
+	if reusableLen := len(p.reusableIDs); reusableLen > 0 { // reuse
+		id = p.reusableIDs[reusableLen-1]
 		p.reusableIDs = p.reusableIDs[:reusableLen-1]
-		p.entityActiveStatus.Set(id)
-		return id
+	} else { // new entity
+		// entity 0 is unused
+		p.TotalEntities++
+		id = p.TotalEntities
 	}
-	// new entity
-	// entity 0 is unused
-	p.TotalEntities++
-	id := p.TotalEntities
+	if spawn {
+		p.spawn(id)
+	}
+	var generation Generation // slots past the capacity were never used
+	if id < p.capacity {
+		generation = p.generations[id]
+	}
+	return newEntity(id, generation), nil
+}
+
+// mark a reserved slot as alive.
+// must be called with the write lock held
+func (p *Pool) spawn(id uint32) {
 	p.entityActiveStatus.Set(id)
-	return id
+	p.modified++
+	p.spawned++
+	p.spawnOrder[id] = p.spawned
+	if p.policy == PolicyEvictOldest {
+		p.queueSpawn(newEntity(id, p.generations[id]))
+	}
+}
+
+// bring an entity reserved with reserve(false) to life.
+// if its slot is past the capacity, the pool was full when it was reserved,
+// so the capacity policy is applied now
+func (p *Pool) spawnReserved(e Entity) error {
+	id := e.Index()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id >= p.capacity {
+		switch p.policy {
+		case PolicyGrow:
+			p.grow(max(nextCapacity(p.capacity), id+1))
+		case PolicyEvictOldest:
+			oldest := p.oldest()
+			if oldest == 0 { // nothing to evict
+				return ErrPoolFull
+			}
+			p.mu.Unlock()
+			Kill(p, oldest)
+			p.mu.Lock()
+			// retire the evicted slot instead of recycling it,
+			// so the pool holds as many entities as before
+			if i := slices.Index(p.reusableIDs, oldest.Index()); i >= 0 {
+				p.reusableIDs = slices.Delete(p.reusableIDs, i, i+1)
+			}
+			p.grow(id + 1)
+		default:
+			return ErrPoolFull
+		}
+	}
+	p.spawn(id)
+	return nil
+}
+
+// make room for capacity entities in the pool and every storage.
+// must be called with the write lock held
+func (p *Pool) grow(capacity uint32) {
+	if capacity <= p.capacity {
+		return
+	}
+	p.capacity = capacity
+	p.entityActiveStatus.Grow(capacity)
+	p.generations = growSlice(p.generations, capacity)
+	p.spawnOrder = growSlice(p.spawnOrder, capacity)
+	p.masks = growSlice(p.masks, capacity*p.maskWords)
+	for _, st := range p.allStorages {
+		st.Bits().Grow(capacity)
+		switch st := st.(type) {
+		case internalStorage:
+			st.grow(capacity)
+		case growableStorage:
+			st.Grow(capacity)
+		}
+	}
 }
 
-// You only need this if you are storing Entities within components
-func GetGeneration(p *Pool, e Entity) uint32 {
-	return p.generations[e]
+// Get the current generation of the slot this entity points to.
+//
+// This is the same as e.Generation() as long as the entity is alive
+func GetGeneration(p *Pool, e Entity) Generation {
+	return p.generations[e.Index()]
 }
 
 // Give an entity back to the pool, allowing recycling
+//
+// killing a dead or stale entity is a no-op
 func Kill(p *Pool, entities ...Entity) {
 	p.mu.Lock()
 	var toClear []Entity
 	for _, e := range entities {
-		if !p.entityActiveStatus.Get(e) {
+		if !IsAlive(p, e) {
 			continue
 		}
-		p.entityActiveStatus.Clear(e)
-		p.generations[e]++
-		p.reusableIDs = append(p.reusableIDs, e)
+		id := e.Index()
+		p.entityActiveStatus.Clear(id)
+		p.modified++
+		p.generations[id]++
 		toClear = append(toClear, e)
 	}
 	p.mu.Unlock()
 
 	for _, e := range toClear {
-		for _, st := range p.allStorages {
-			if st.bits().Get(e) { // skip zeroing if no bit
-				st.clear(e)
-			}
+		p.clearComponents(e)
+		for _, hook := range p.onKill {
+			hook(e)
 		}
+		p.emit(EntityKilled, e, nil)
+	}
+
+	// only recycle the slots once they are empty,
+	// so hooks that create entities do not get a slot that is being cleared
+	p.mu.Lock()
+	for _, e := range toClear {
+		p.reusableIDs = append(p.reusableIDs, e.Index())
 	}
+	p.mu.Unlock()
 }
 
 // Check if an entity is alive.
 //
-// This is not enough if you are storing entities within components
+// stale entities (whose slot was killed and possibly recycled) are not alive
 func IsAlive(p *Pool, e Entity) bool {
-	return p.entityActiveStatus.Get(e)
+	id := e.Index()
+	if id == 0 || id >= p.capacity {
+		return false
+	}
+	return p.entityActiveStatus.Get(id) && p.generations[id] == e.Generation()
 }
 
 // Check if internal generation for this Entity matches the generation you are storing
 //
-// You only need this if you are storing entities
+// Deprecated: entities carry their own generation, use [IsAlive] instead
 func IsAliveWithGeneration(p *Pool, e Entity, generation Generation) bool {
-	return generation == p.generations[e]
+	return generation == p.generations[e.Index()]
 }
 
 // Get a component storage, allocate it if not already
 func GetStorage[Component any](p *Pool) *Storage[Component] {
+	return Register[Component](p, DenseStorage)
+}
+
+// Register Component with a kind of storage, and get the storage.
+//
+// Call it before Component is used, because if Component already has a storage,
+// that storage is returned regardless of its kind.
+// Every kind of storage has the same API, so systems do not change.
+func Register[Component any](p *Pool, kind StorageKind) *Storage[Component] {
 	nilptr := (*Component)(nil) // Key: nil pointer to Component
 
 	p.mu.RLock()
 	st, ok := p.storages[nilptr]
 	p.mu.RUnlock()
 	if ok {
-		return st.(*Storage[Component])
+		return asStorage[Component](st)
 	}
 
 	// Not found, acquire write lock
@@ -132,37 +340,74 @@ func GetStorage[Component any](p *Pool) *Storage[Component] {
 
 	// Double check after acquiring write lock
 	if st, ok := p.storages[nilptr]; ok {
-		return st.(*Storage[Component])
+		return asStorage[Component](st)
 	}
 
 	// Still not present, safe to create
-	newSt := newStorage[Component](p.capacity)
+	newSt := newStorage[Component](p.capacity, kind)
 	// pass []Entity, used for queries
 	newSt.parentPoolEntities = &p.poolEntititySlices
+	newSt.pool = p
+	newSt.ID = len(p.allStorages)
+	p.growMasks(newSt.ID + 1)
 	p.storages[nilptr] = newSt
 	p.allStorages = append(p.allStorages, newSt)
 	return newSt
 }
 
+// panic with a helpful message if Component was registered with RegisterStorage
+func asStorage[Component any](st ComponentStorage) *Storage[Component] {
+	s, ok := st.(*Storage[Component])
+	if !ok {
+		panic(fmt.Sprintf("ecs: %v has a custom storage, use GetComponentStorage", st.Type()))
+	}
+	return s
+}
+
 // Add a component to an entity.
 //
-// adding to a dead entity is a no-op. but note that this does not validate generations
+// adding a component the entity already has updates it instead.
+// adding to a dead or stale entity is a no-op
 func Add[Component any](p *Pool, e Entity, c Component) {
 	if !IsAlive(p, e) {
 		return
 	}
-	st := GetStorage[Component](p)
-	st.bits().Set(e)
-	st.Update(e, c)
+	registered := GetComponentStorage[Component](p)
+	st, ok := registered.(*Storage[Component])
+	if !ok {
+		if registered != nil {
+			addCustom(p, e, c, registered)
+			return
+		}
+		st = GetStorage[Component](p)
+	}
+	id := e.Index()
+	if st.Bits().Get(id) { // already added, so this is an update
+		st.Update(e, c)
+		return
+	}
+	st.Bits().Set(id)
+	p.mask(id)[st.ID/64] |= 1 << (st.ID % 64)
+	st.modified++
+	st.components.add(id, c)
+	st.markAdded(id)
+	for _, hook := range st.onAdd {
+		hook(p, e, c)
+	}
+	p.emitComponent(ComponentAdded, e, st)
 }
 
 // Remove a component from an entity
 //
-// removing from a dead entity is a no-op. but note that this does not validate generations
+// removing from a dead or stale entity is a no-op
 func Remove[Component any](p *Pool, e Entity) {
 	if !IsAlive(p, e) {
 		return
 	}
-	st := GetStorage[Component](p)
-	st.clear(e)
+	st := GetComponentStorage[Component](p)
+	if st != nil && st.Bits().Get(e.Index()) {
+		st.Clear(e)
+		st.Bits().Clear(e.Index()) // custom storages may leave the bit to the pool
+		p.emitComponent(ComponentRemoved, e, st)
+	}
 }
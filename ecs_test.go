@@ -17,6 +17,39 @@ func TestNewPool(t *testing.T) {
 	}
 }
 
+// Test that the pool and its storages grow past the initial capacity
+func TestPoolGrowth(t *testing.T) {
+	type Comp struct{ Value int }
+	p := New(2)
+	st := GetStorage[Comp](p)
+	var es []Entity
+	for i := range 100 {
+		e := NewEntity(p)
+		Add(p, e, Comp{Value: i})
+		es = append(es, e)
+	}
+	if p.capacity <= 100 {
+		t.Errorf("expected capacity to grow past 100, got %d", p.capacity)
+	}
+	for i, e := range es {
+		if !IsAlive(p, e) {
+			t.Fatalf("entity %d should be alive after growth", e)
+		}
+		if got := st.Get(e).Value; got != i {
+			t.Errorf("expected component %d to survive growth, got %d", i, got)
+		}
+	}
+	// storages created after growth use the new capacity
+	type Late struct{ Value int }
+	Add(p, es[99], Late{Value: 7})
+	if got := GetStorage[Late](p).Get(es[99]).Value; got != 7 {
+		t.Errorf("expected late storage value 7, got %d", got)
+	}
+	if n := len(st.All()); n != 100 {
+		t.Errorf("expected 100 entities in query, got %d", n)
+	}
+}
+
 // Test entity lifecycle: creation, alive status, killing, recycling, and generations
 func TestEntityLifecycle(t *testing.T) {
 	p := New(3)
@@ -39,14 +72,55 @@ func TestEntityLifecycle(t *testing.T) {
 	if gen != 1 {
 		t.Errorf("expected generation 1 after kill, got %d", gen)
 	}
-	// reuse should give same ID
+	// reuse should give same ID with a new generation
 	e1r := NewEntity(p)
-	if e1r != e1 {
-		t.Errorf("expected recycled ID %d, got %d", e1, e1r)
+	if e1r.Index() != e1.Index() {
+		t.Errorf("expected recycled ID %d, got %d", e1.Index(), e1r.Index())
+	}
+	if e1r == e1 {
+		t.Errorf("recycled entity should not equal the stale handle")
 	}
 	if !IsAlive(p, e1r) {
 		t.Errorf("recycled entity should be alive")
 	}
+	if IsAlive(p, e1) {
+		t.Errorf("stale handle %d should not be alive after recycling", e1)
+	}
+}
+
+// Test that stale handles are rejected by every call
+func TestStaleEntity(t *testing.T) {
+	type Comp struct{ Value int }
+	p := New(1)
+	st := GetStorage[Comp](p)
+	stale := NewEntity(p)
+	Add(p, stale, Comp{Value: 1})
+	Kill(p, stale)
+	e := NewEntity(p)
+	if e.Index() != stale.Index() {
+		t.Fatalf("expected slot %d to be recycled, got %d", stale.Index(), e.Index())
+	}
+	Add(p, e, Comp{Value: 2})
+
+	Add(p, stale, Comp{Value: 3})
+	st.Update(stale, Comp{Value: 4})
+	if got := st.Get(e).Value; got != 2 {
+		t.Errorf("stale handle overwrote component, got %d", got)
+	}
+	if got := st.Get(stale).Value; got != 0 {
+		t.Errorf("expected zero value for stale handle, got %d", got)
+	}
+	if st.EntityHasComponent(stale) {
+		t.Errorf("stale handle should not have components")
+	}
+	Remove[Comp](p, stale)
+	Kill(p, stale)
+	if !IsAlive(p, e) || !st.EntityHasComponent(e) {
+		t.Errorf("stale handle removed the component or killed the new entity")
+	}
+	if all := st.All(); len(all) != 1 || all[0] != e {
+		t.Errorf("expected query to return the current handle %d, got %v", e, all)
+	}
 }
 
 // Test IsAliveWithGeneration
@@ -173,3 +247,30 @@ func TestStorageQueries(t *testing.T) {
 	}
 }
 
+// Test in-place component access with GetPtr and TryGetPtr
+func TestGetPtr(t *testing.T) {
+	type Comp struct{ Value int }
+	p := New(2)
+	st := GetStorage[Comp](p)
+	e := NewEntity(p)
+	if _, ok := st.TryGetPtr(e); ok {
+		t.Errorf("TryGetPtr should fail without the component")
+	}
+	Add(p, e, Comp{Value: 1})
+	st.GetPtr(e).Value++
+	c, ok := st.TryGetPtr(e)
+	if !ok {
+		t.Fatalf("TryGetPtr should succeed with the component")
+	}
+	c.Value++
+	if got := st.Get(e).Value; got != 3 {
+		t.Errorf("expected value 3 after modifying through pointers, got %d", got)
+	}
+	Kill(p, e)
+	if st.GetPtr(e) != nil {
+		t.Errorf("GetPtr should return nil for a dead entity")
+	}
+	if c, ok := st.TryGetPtr(e); ok || c != nil {
+		t.Errorf("TryGetPtr should return nil, false for a dead entity")
+	}
+}
@@ -0,0 +1,115 @@
+package ecs
+
+import "sync"
+
+// A queue of events of type T, used by systems to talk to each other.
+//
+// Events are double-buffered: an event stays readable for the frame it was sent in
+// and the frame after it, so every system gets to read it once,
+// no matter which order the systems run in.
+// Frames are ended with [UpdateEvents], which [Scheduler.Run] calls for you.
+//
+// It is safe to send events from multiple goroutines
+type Events[T any] struct {
+	mu       sync.Mutex
+	previous []T    // events sent last frame
+	current  []T    // events sent this frame
+	start    uint64 // sequence number of previous[0]
+}
+
+type eventQueue interface {
+	update()
+}
+
+// Get the event queue for T, create it if not already.
+//
+// the queue is stored in the pool like a resource
+func GetEvents[T any](p *Pool) *Events[T] {
+	nilptr := (*Events[T])(nil)
+	p.mu.RLock()
+	r, ok := p.resources[nilptr]
+	p.mu.RUnlock()
+	if ok {
+		return r.(*Events[T])
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r, ok := p.resources[nilptr]; ok {
+		return r.(*Events[T])
+	}
+	ev := &Events[T]{}
+	p.resources[nilptr] = ev
+	p.eventQueues = append(p.eventQueues, ev)
+	return ev
+}
+
+// Send an event to every reader
+func (ev *Events[T]) Send(event T) {
+	ev.mu.Lock()
+	ev.current = append(ev.current, event)
+	ev.mu.Unlock()
+}
+
+// Send an event of type T to every reader
+func SendEvent[T any](p *Pool, event T) {
+	GetEvents[T](p).Send(event)
+}
+
+// drop the events of the previous frame, and start a new frame
+func (ev *Events[T]) update() {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	ev.start += uint64(len(ev.previous))
+	ev.previous, ev.current = ev.current, ev.previous[:0]
+}
+
+// End the frame for every event queue in the pool.
+//
+// events sent before the previous call are dropped
+func UpdateEvents(p *Pool) {
+	p.mu.RLock()
+	queues := p.eventQueues
+	p.mu.RUnlock()
+	for _, ev := range queues {
+		ev.update()
+	}
+}
+
+// An EventReader reads every event of an [Events] queue once.
+//
+// each system should have its own reader
+type EventReader[T any] struct {
+	events *Events[T]
+	next   uint64 // sequence number of the next unread event
+	read   []T    // reused by Read
+}
+
+// Create a reader for events of type T.
+// the reader starts at the oldest event that is still in the queue
+func NewEventReader[T any](p *Pool) *EventReader[T] {
+	ev := GetEvents[T](p)
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	return &EventReader[T]{events: ev, next: ev.start}
+}
+
+// Get the events that this reader has not read yet, in the order they were sent.
+//
+// The slice is reused by the reader, so do not keep it around
+func (r *EventReader[T]) Read() []T {
+	ev := r.events
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	r.read = r.read[:0]
+	// skip events that were dropped before they were read
+	next := max(r.next, ev.start)
+	if i := next - ev.start; i < uint64(len(ev.previous)) {
+		r.read = append(r.read, ev.previous[i:]...)
+		next = ev.start + uint64(len(ev.previous))
+	}
+	i := next - ev.start - uint64(len(ev.previous))
+	r.read = append(r.read, ev.current[i:]...)
+	r.next = ev.start + uint64(len(ev.previous)+len(ev.current))
+	return r.read
+}
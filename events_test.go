@@ -0,0 +1,59 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that each reader sees every event once, and events expire after two frames
+func TestEvents(t *testing.T) {
+	type Collision struct{ A, B int }
+	type GameOver struct{}
+	p := New(1)
+	early := NewEventReader[Collision](p)
+	late := NewEventReader[Collision](p)
+
+	SendEvent(p, Collision{1, 2})
+	GetEvents[Collision](p).Send(Collision{3, 4})
+	if got := early.Read(); !slices.Equal(got, []Collision{{1, 2}, {3, 4}}) {
+		t.Errorf("expected both events, got %v", got)
+	}
+	if got := early.Read(); len(got) != 0 {
+		t.Errorf("expected events to be read once, got %v", got)
+	}
+	UpdateEvents(p)
+
+	// a reader that runs before the sender next frame still sees last frame's events
+	SendEvent(p, Collision{5, 6})
+	if got := late.Read(); !slices.Equal(got, []Collision{{1, 2}, {3, 4}, {5, 6}}) {
+		t.Errorf("expected all three events, got %v", got)
+	}
+	if got := early.Read(); !slices.Equal(got, []Collision{{5, 6}}) {
+		t.Errorf("expected only the new event, got %v", got)
+	}
+
+	// events are dropped after two frames
+	SendEvent(p, Collision{7, 8})
+	UpdateEvents(p)
+	UpdateEvents(p)
+	if got := NewEventReader[Collision](p).Read(); len(got) != 0 {
+		t.Errorf("expected events to be dropped, got %v", got)
+	}
+	if got := early.Read(); len(got) != 0 {
+		t.Errorf("expected missed events to be skipped, got %v", got)
+	}
+
+	// the scheduler ends the frame
+	gameOver := NewEventReader[GameOver](p)
+	s := NewScheduler()
+	s.AddSystem(Update, "collision", func(p *Pool, dt float64) { SendEvent(p, GameOver{}) })
+	for range 3 {
+		if err := s.Run(p, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the frame of the last run has ended, so only its event is left
+	if n := len(gameOver.Read()); n != 1 {
+		t.Errorf("expected 1 event, got %d", n)
+	}
+}
@@ -0,0 +1,182 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// A term of a [Filter]: any [ComponentStorage], a nested [Filter],
+// or the terms returned by [Added], [Changed] and [Pair]
+type Term interface {
+	// check if an entity matches the term
+	Has(e Entity) bool
+}
+
+// how terms are evaluated: a storage or a nested filter
+type matcher interface {
+	// the i-th group of 64 entities that match, as a bitmask
+	word(i int) uint64
+	// changes whenever the set of matching entities might have changed
+	version() uint64
+	// the pool the entities belong to
+	owner() *Pool
+}
+
+// A Filter combines storages into one query.
+//
+//	// has Position and Velocity, has Sprite or Mesh, but not Frozen
+//	f := ecs.NewFilter(pool).
+//		With(POSITION, VELOCITY).
+//		AnyOf(SPRITE, MESH).
+//		Without(FROZEN)
+//	for e := range f.Iter() {
+//		...
+//	}
+//
+// Filters can be nested by passing a filter as a term of another filter.
+// The result is computed 64 entities at a time straight from the storage bitsets,
+// so no intermediate sets are allocated.
+type Filter struct {
+	pool    *Pool
+	with    []matcher
+	without []matcher
+	anyOf   [][]matcher // each group needs at least one match
+}
+
+// Create a filter that matches every alive entity
+func NewFilter(p *Pool) *Filter {
+	return &Filter{pool: p}
+}
+
+// Only match entities that match every term
+func (f *Filter) With(terms ...Term) *Filter {
+	f.with = append(f.with, f.matchers(terms)...)
+	return f
+}
+
+// Only match entities that match none of the terms
+func (f *Filter) Without(terms ...Term) *Filter {
+	f.without = append(f.without, f.matchers(terms)...)
+	return f
+}
+
+// Only match entities that match at least one of the terms.
+//
+// calling AnyOf multiple times requires a match from every group
+func (f *Filter) AnyOf(terms ...Term) *Filter {
+	f.anyOf = append(f.anyOf, f.matchers(terms))
+	return f
+}
+
+// the terms of this package are matchers already,
+// custom storages are read through their bitset
+func (f *Filter) matchers(terms []Term) []matcher {
+	matchers := make([]matcher, len(terms))
+	for i, t := range terms {
+		switch t := t.(type) {
+		case matcher:
+			matchers[i] = t
+		case ComponentStorage:
+			matchers[i] = customMatcher{st: t, pool: f.pool}
+		default:
+			panic(fmt.Sprintf("ecs: %T cannot be used in a filter, it is not a ComponentStorage", t))
+		}
+	}
+	return matchers
+}
+
+// check if an entity matches the filter
+func (f *Filter) Has(e Entity) bool { return matches(f, e) }
+
+// check if an alive entity is in the bitmask of a matcher
+func matches(m matcher, e Entity) bool {
+	if !IsAlive(m.owner(), e) {
+		return false
+	}
+	id := e.Index()
+	return m.word(int(id/64))&(1<<(id%64)) != 0
+}
+
+func (f *Filter) word(i int) uint64 {
+	w := f.pool.entityActiveStatus.word(i)
+	for _, t := range f.with {
+		if w == 0 {
+			return 0
+		}
+		w &= t.word(i)
+	}
+	for _, t := range f.without {
+		if w == 0 {
+			return 0
+		}
+		w &^= t.word(i)
+	}
+	for _, group := range f.anyOf {
+		if w == 0 {
+			return 0
+		}
+		var matched uint64
+		for _, t := range group {
+			matched |= t.word(i)
+		}
+		w &= matched
+	}
+	return w
+}
+
+func (f *Filter) owner() *Pool { return f.pool }
+
+// the versions only ever go up, so the sum changes whenever one of them does.
+// entities only match With and AnyOf terms through their components,
+// and killing an entity bumps the version of its storages,
+// so the pool version is only needed when every alive entity can match
+func (f *Filter) version() uint64 {
+	var v uint64
+	if len(f.with) == 0 && len(f.anyOf) == 0 {
+		v = f.pool.modified
+	}
+	for _, t := range f.with {
+		v += t.version()
+	}
+	for _, t := range f.without {
+		v += t.version()
+	}
+	for _, group := range f.anyOf {
+		for _, t := range group {
+			v += t.version()
+		}
+	}
+	return v
+}
+
+// Iterate over all entities that match the filter.
+//
+// the filter is evaluated while iterating, so changes to entities
+// that have not been visited yet are seen by the loop
+func (f *Filter) Iter() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		p := f.pool
+		for wi := 0; wi < len(p.entityActiveStatus.bits); wi++ {
+			w := f.word(wi)
+			base := uint32(wi) * 64
+			for w != 0 {
+				t := bits.TrailingZeros64(w)
+				id := base + uint32(t)
+				if !yield(newEntity(id, p.generations[id])) {
+					return
+				}
+				w &^= 1 << t
+			}
+		}
+	}
+}
+
+// All entities that match the filter
+func (f *Filter) Entities() []Entity {
+	var entities []Entity
+	for e := range f.Iter() {
+		entities = append(entities, e)
+	}
+	return entities
+}
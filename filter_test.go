@@ -0,0 +1,130 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test combining With, Without, AnyOf and nested filters
+func TestFilter(t *testing.T) {
+	type Position struct{}
+	type Velocity struct{}
+	type Sprite struct{}
+	type Mesh struct{}
+	type Frozen struct{}
+	p := New(10)
+	POSITION, VELOCITY, SPRITE, MESH, FROZEN :=
+		GetStorage5[Position, Velocity, Sprite, Mesh, Frozen](p)
+
+	sprite := NewEntity(p)
+	Add3(p, sprite, Position{}, Velocity{}, Sprite{})
+	mesh := NewEntity(p)
+	Add3(p, mesh, Position{}, Velocity{}, Mesh{})
+	frozen := NewEntity(p)
+	Add4(p, frozen, Position{}, Velocity{}, Sprite{}, Frozen{})
+	invisible := NewEntity(p)
+	Add2(p, invisible, Position{}, Velocity{})
+	still := NewEntity(p)
+	Add2(p, still, Position{}, Sprite{})
+
+	got := NewFilter(p).
+		With(POSITION, VELOCITY).
+		AnyOf(SPRITE, MESH).
+		Without(FROZEN).
+		Entities()
+	if want := []Entity{sprite, mesh}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// nested: (Position and Velocity) or Frozen
+	moving := NewFilter(p).With(POSITION, VELOCITY)
+	got = NewFilter(p).AnyOf(moving, FROZEN).Without(MESH).Entities()
+	if want := []Entity{sprite, frozen, invisible}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Without alone only matches alive entities
+	Kill(p, still)
+	got = NewFilter(p).Without(VELOCITY).Entities()
+	if len(got) != 0 {
+		t.Errorf("expected no entities, got %v", got)
+	}
+}
+
+// Test that iterating a filter does not allocate
+func TestFilterAllocs(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	p := New(1000)
+	for i := range 1000 {
+		e := NewEntity(p)
+		Add2(p, e, A{}, B{})
+		if i%2 == 0 {
+			Add(p, e, C{})
+		}
+	}
+	f := NewFilter(p).With(GetStorage2[A, B](p)).Without(GetStorage[C](p))
+	allocs := testing.AllocsPerRun(100, func() {
+		for e := range f.Iter() {
+			_ = e
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations per iteration, got %v", allocs)
+	}
+}
+
+// Test that cached queries only recompute when a storage changed
+func TestCachedQuery(t *testing.T) {
+	type A struct{ X int }
+	type B struct{}
+	p := New(10)
+	stA, stB := GetStorage2[A, B](p)
+	e1 := NewEntity(p)
+	Add2(p, e1, A{}, B{})
+	e2 := NewEntity(p)
+	Add(p, e2, A{})
+
+	q := NewCachedQuery(NewFilter(p).With(stA).Without(stB))
+	if got := q.Entities(); !slices.Equal(got, []Entity{e2}) {
+		t.Fatalf("expected [%d], got %v", e2, got)
+	}
+	// updating a value does not change membership
+	v := q.version
+	stA.Update(e2, A{X: 1})
+	Add(p, e2, A{X: 2})
+	q.Entities()
+	if q.version != v {
+		t.Errorf("query should not be invalidated by updating a component")
+	}
+
+	Remove[B](p, e1)
+	if got := q.Entities(); !slices.Equal(got, []Entity{e1, e2}) {
+		t.Errorf("expected [%d %d] after Remove, got %v", e1, e2, got)
+	}
+	Kill(p, e1)
+	if got := q.Entities(); !slices.Equal(got, []Entity{e2}) {
+		t.Errorf("expected [%d] after Kill, got %v", e2, got)
+	}
+	e3 := NewEntity(p)
+	Add(p, e3, A{})
+	if got := q.Entities(); !slices.Equal(got, []Entity{e3, e2}) {
+		t.Errorf("expected [%d %d] after Add, got %v", e3, e2, got)
+	}
+
+	// entities without components do not change queries with With terms
+	v = q.version
+	NewEntity(p)
+	q.Entities()
+	if q.version != v {
+		t.Errorf("query should not be invalidated by an unrelated entity")
+	}
+	// but they match queries without them
+	notB := NewCachedQuery(NewFilter(p).Without(stB))
+	before := len(notB.Entities())
+	NewEntity(p)
+	if got := len(notB.Entities()); got != before+1 {
+		t.Errorf("expected %d entities without B, got %d", before+1, got)
+	}
+}
@@ -0,0 +1,150 @@
+package ecs
+
+import (
+	"errors"
+	"iter"
+	"slices"
+)
+
+// returned by [SetParent] when the parent is the child itself or one of its descendants
+var ErrHierarchyCycle = errors.New("ecs: entity can not be its own ancestor")
+
+// component of entities that have a parent
+type childOf struct {
+	parent Entity
+}
+
+// component of entities that have children
+type childList struct {
+	children []Entity
+}
+
+// get the storages used for the hierarchy.
+// the first call makes killed children leave the children of their parent,
+// and hides the storages from observers
+func hierarchy(p *Pool) (*Storage[childOf], *Storage[childList]) {
+	CHILDOF, CHILDLIST := GetStorage2[childOf, childList](p)
+	p.hierarchyOnce.Do(func() {
+		CHILDOF.internal = true
+		CHILDLIST.internal = true
+		OnRemove(p, func(p *Pool, child Entity, c childOf) {
+			detach(p, child, c.parent)
+		})
+	})
+	return CHILDOF, CHILDLIST
+}
+
+// remove child from the children of parent
+func detach(p *Pool, child, parent Entity) {
+	list, ok := GetStorage[childList](p).TryGetPtr(parent)
+	if !ok {
+		return
+	}
+	list.children = slices.DeleteFunc(list.children, func(e Entity) bool { return e == child })
+	if len(list.children) == 0 {
+		Remove[childList](p, parent)
+	}
+}
+
+// Make parent the parent of child, replacing its old parent.
+//
+// does nothing if either entity is dead or stale.
+// returns [ErrHierarchyCycle] if parent is child or one of its descendants
+func SetParent(p *Pool, child, parent Entity) error {
+	if !IsAlive(p, child) || !IsAlive(p, parent) {
+		return nil
+	}
+	if parent == child {
+		return ErrHierarchyCycle
+	}
+	for ancestor := range Ancestors(p, parent) {
+		if ancestor == child {
+			return ErrHierarchyCycle
+		}
+	}
+	CHILDOF, CHILDLIST := hierarchy(p)
+	if old, ok := CHILDOF.TryGetPtr(child); ok {
+		if old.parent == parent {
+			return nil
+		}
+		detach(p, child, old.parent)
+	}
+	Add(p, child, childOf{parent: parent})
+	if list, ok := CHILDLIST.TryGetPtr(parent); ok {
+		list.children = append(list.children, child)
+	} else {
+		Add(p, parent, childList{children: []Entity{child}})
+	}
+	return nil
+}
+
+// Remove the parent of child, making it a root.
+//
+// does nothing if child is dead, stale or has no parent
+func RemoveParent(p *Pool, child Entity) {
+	CHILDOF, _ := hierarchy(p)
+	if old, ok := CHILDOF.TryGetPtr(child); ok {
+		detach(p, child, old.parent)
+		Remove[childOf](p, child)
+	}
+}
+
+// Get the parent of an entity.
+//
+// returns 0 if the entity has no parent, or its parent was killed
+func Parent(p *Pool, e Entity) Entity {
+	CHILDOF, _ := hierarchy(p)
+	c, ok := CHILDOF.TryGetPtr(e)
+	if !ok || !IsAlive(p, c.parent) {
+		return 0
+	}
+	return c.parent
+}
+
+// Get a copy of the children of an entity, in the order they were added
+func Children(p *Pool, e Entity) []Entity {
+	_, CHILDLIST := hierarchy(p)
+	list, ok := CHILDLIST.TryGetPtr(e)
+	if !ok {
+		return nil
+	}
+	return slices.Clone(list.children)
+}
+
+// Iterate over the parent of an entity, its parent, and so on up to the root
+func Ancestors(p *Pool, e Entity) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		for parent := Parent(p, e); parent != 0; parent = Parent(p, parent) {
+			if !yield(parent) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over the children of an entity, their children, and so on.
+// each child is visited before its own children
+func Descendants(p *Pool, e Entity) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		var visit func(e Entity) bool
+		visit = func(e Entity) bool {
+			for _, child := range Children(p, e) {
+				if !yield(child) || !visit(child) {
+					return false
+				}
+			}
+			return true
+		}
+		visit(e)
+	}
+}
+
+// Kill entities along with all of their descendants
+func KillRecursive(p *Pool, entities ...Entity) {
+	var all []Entity
+	for _, e := range entities {
+		all = append(all, e)
+		all = slices.AppendSeq(all, Descendants(p, e))
+	}
+	Kill(p, all...)
+}
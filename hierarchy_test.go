@@ -0,0 +1,91 @@
+package ecs
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// Test building, changing and tearing down a hierarchy
+func TestHierarchy(t *testing.T) {
+	p := New(10)
+	player, weapon, scope, hat := NewEntity(p), NewEntity(p), NewEntity(p), NewEntity(p)
+	other := NewEntity(p)
+	for _, link := range [][2]Entity{{weapon, player}, {scope, weapon}, {hat, player}} {
+		if err := SetParent(p, link[0], link[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := Children(p, player); !slices.Equal(got, []Entity{weapon, hat}) {
+		t.Errorf("expected children [%d %d], got %v", weapon, hat, got)
+	}
+	if got := Parent(p, scope); got != weapon {
+		t.Errorf("expected parent %d, got %d", weapon, got)
+	}
+	if got := slices.Collect(Ancestors(p, scope)); !slices.Equal(got, []Entity{weapon, player}) {
+		t.Errorf("expected ancestors [%d %d], got %v", weapon, player, got)
+	}
+	if got := slices.Collect(Descendants(p, player)); !slices.Equal(got, []Entity{weapon, scope, hat}) {
+		t.Errorf("expected descendants [%d %d %d], got %v", weapon, scope, hat, got)
+	}
+	if err := SetParent(p, player, scope); !errors.Is(err, ErrHierarchyCycle) {
+		t.Errorf("expected ErrHierarchyCycle, got %v", err)
+	}
+
+	// moving and killing children updates the parent
+	SetParent(p, hat, other)
+	if got := Children(p, player); !slices.Equal(got, []Entity{weapon}) {
+		t.Errorf("expected children [%d] after moving hat, got %v", weapon, got)
+	}
+	Kill(p, hat)
+	if got := Children(p, other); len(got) != 0 {
+		t.Errorf("expected killed child to leave its parent, got %v", got)
+	}
+	RemoveParent(p, scope)
+	if Parent(p, scope) != 0 || len(Children(p, weapon)) != 0 {
+		t.Errorf("expected scope to be a root after RemoveParent")
+	}
+	SetParent(p, scope, weapon)
+
+	// killing a parent orphans its children, and stale parents are not returned
+	Kill(p, weapon)
+	recycled := NewEntity(p)
+	if recycled.Index() != weapon.Index() {
+		t.Fatalf("expected slot %d to be recycled", weapon.Index())
+	}
+	if got := Parent(p, scope); got != 0 {
+		t.Errorf("expected no parent after parent was killed, got %d", got)
+	}
+
+	// KillRecursive tears down the whole subtree
+	SetParent(p, scope, recycled)
+	SetParent(p, recycled, player)
+	KillRecursive(p, player)
+	for _, e := range []Entity{player, recycled, scope} {
+		if IsAlive(p, e) {
+			t.Errorf("expected %d to be killed with its ancestor", e)
+		}
+	}
+	if !IsAlive(p, other) {
+		t.Errorf("unrelated entity should survive")
+	}
+}
+
+// Test that observers do not hear about the components of the hierarchy
+func TestHierarchyObserved(t *testing.T) {
+	p := New(8)
+	var events []LifecycleEvent
+	Observe(p, func(_ *Pool, ev LifecycleEvent) {
+		if ev.Kind == ComponentAdded || ev.Kind == ComponentRemoved {
+			events = append(events, ev)
+		}
+	})
+	parent, child, other := NewEntity(p), NewEntity(p), NewEntity(p)
+	SetParent(p, child, parent)
+	SetParent(p, other, parent)
+	RemoveParent(p, other)
+	KillRecursive(p, parent)
+	if len(events) != 0 {
+		t.Errorf("expected no component events, got %v", events)
+	}
+}
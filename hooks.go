@@ -0,0 +1,38 @@
+package ecs
+
+// A hook is called when a component of an entity is added, set, or removed.
+//
+// c is the new value for add and set hooks,
+// and the old value for remove hooks.
+type Hook[Component any] func(p *Pool, e Entity, c Component)
+
+// Call hook whenever an entity gains Component through [Add]
+func OnAdd[Component any](p *Pool, hook Hook[Component]) {
+	st := GetStorage[Component](p)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st.onAdd = append(st.onAdd, hook)
+}
+
+// Call hook whenever Component is changed through [Storage.Update],
+// or by calling [Add] on an entity that already has it.
+//
+// changes made through pointers, eg. [Storage.GetPtr], do not call the hook
+func OnSet[Component any](p *Pool, hook Hook[Component]) {
+	st := GetStorage[Component](p)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st.onSet = append(st.onSet, hook)
+}
+
+// Call hook whenever an entity loses Component through [Remove] or [Kill],
+// before the component is zeroed.
+//
+// when called from Kill the entity is already dead,
+// so use the component value instead of reading storages
+func OnRemove[Component any](p *Pool, hook Hook[Component]) {
+	st := GetStorage[Component](p)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st.onRemove = append(st.onRemove, hook)
+}
@@ -0,0 +1,83 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that hooks fire from Add, Update, Remove and Kill
+func TestHooks(t *testing.T) {
+	type Texture struct{ ID int }
+	p := New(4)
+	var events []string
+	record := func(kind string) Hook[Texture] {
+		return func(p *Pool, e Entity, c Texture) {
+			events = append(events, kind+":"+string(rune('0'+c.ID)))
+		}
+	}
+	OnAdd(p, record("add"))
+	OnSet(p, record("set"))
+	OnRemove(p, func(p *Pool, e Entity, c Texture) {
+		// the old value is still there before zeroing
+		if got := GetStorage[Texture](p).components.get(e.Index()); got != c {
+			t.Errorf("expected component %v before zeroing, got %v", c, got)
+		}
+		record("remove")(p, e, c)
+	})
+
+	e1, e2 := NewEntity(p), NewEntity(p)
+	Add(p, e1, Texture{ID: 1})
+	Add(p, e1, Texture{ID: 2})
+	GetStorage[Texture](p).Update(e1, Texture{ID: 3})
+	GetStorage[Texture](p).Update(e2, Texture{ID: 9}) // e2 does not have the component
+	Remove[Texture](p, e1)
+	Remove[Texture](p, e1)
+	Add(p, e2, Texture{ID: 4})
+	Kill(p, e2)
+	Kill(p, e2)
+
+	want := []string{"add:1", "set:2", "set:3", "remove:3", "add:4", "remove:4"}
+	if !slices.Equal(events, want) {
+		t.Errorf("expected %v, got %v", want, events)
+	}
+}
+
+// Test that a remove hook can create an entity while its entity is being killed
+func TestHookSpawnsDuringKill(t *testing.T) {
+	type Health struct{ HP int }
+	p := New(4)
+	var spawned Entity
+	OnRemove(p, func(p *Pool, e Entity, c Health) {
+		if spawned == 0 {
+			spawned = NewEntity(p)
+			Add(p, spawned, Health{HP: 9})
+		}
+	})
+	e := NewEntity(p)
+	Add(p, e, Health{HP: 1})
+	Kill(p, e)
+	if spawned.Index() == e.Index() {
+		t.Errorf("the slot of a dying entity should not be reused by its hooks")
+	}
+	if got, ok := GetStorage[Health](p).TryGetPtr(spawned); !ok || got.HP != 9 {
+		t.Errorf("expected the spawned entity to have Health{9}, got %v", got)
+	}
+	if recycled := NewEntity(p); recycled.Index() != e.Index() {
+		t.Errorf("expected the slot %d to be recycled after Kill, got %d", e.Index(), recycled.Index())
+	}
+}
+
+// Test that clearing an entity without the component does not call remove hooks
+func TestClearWithoutComponent(t *testing.T) {
+	type Texture struct{ ID int }
+	p := New(4)
+	removed := 0
+	OnRemove(p, func(p *Pool, e Entity, c Texture) { removed++ })
+	TEXTURE := GetStorage[Texture](p)
+	e := NewEntity(p)
+	version := TEXTURE.version()
+	TEXTURE.Clear(e)
+	if removed != 0 || TEXTURE.version() != version {
+		t.Errorf("Clear should do nothing for an entity without the component")
+	}
+}
@@ -0,0 +1,109 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that the iterators match the slice queries
+func TestStorageIterators(t *testing.T) {
+	type A struct{ X int }
+	type B struct{ Y int }
+	p := New(200)
+	for i := range 200 {
+		e := NewEntity(p)
+		if i%2 == 0 {
+			Add(p, e, A{})
+		}
+		if i%3 == 0 {
+			Add(p, e, B{})
+		}
+	}
+	stA, stB := GetStorage2[A, B](p)
+	cases := []struct {
+		name string
+		got  []Entity
+		want []Entity
+	}{
+		{"All", slices.Collect(stA.IterAll()), stA.All()},
+		{"And", slices.Collect(stA.IterAnd(stB)), stA.And(stB)},
+		{"ButNot", slices.Collect(stA.IterButNot(stB)), stA.ButNot(stB)},
+		{"Or", slices.Collect(stA.IterOr(stB)), stA.Or(stB)},
+	}
+	for _, c := range cases {
+		if !slices.Equal(c.got, c.want) {
+			t.Errorf("%s mismatch: expected %v, got %v", c.name, c.want, c.got)
+		}
+	}
+	// break stops early
+	n := 0
+	for range stA.IterAll() {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("expected to stop after 3 entities, got %d", n)
+	}
+}
+
+// Test that iterating does not allocate
+func TestStorageIteratorsAllocs(t *testing.T) {
+	type A struct{ X int }
+	type B struct{ Y int }
+	p := New(1000)
+	for range 1000 {
+		Add2(p, NewEntity(p), A{}, B{})
+	}
+	stA, stB := GetStorage2[A, B](p)
+	allocs := testing.AllocsPerRun(100, func() {
+		for e := range stA.IterAnd(stB) {
+			_ = e
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations per iteration, got %v", allocs)
+	}
+}
+
+// Test that typed queries yield pointers into the storages
+func TestQuery(t *testing.T) {
+	type Position struct{ X, Y float64 }
+	type Velocity struct{ X, Y float64 }
+	type Frozen struct{}
+	p := New(10)
+	moving := NewEntity(p)
+	Add2(p, moving, Position{}, Velocity{X: 1, Y: 2})
+	still := NewEntity(p)
+	Add(p, still, Position{X: 5})
+	frozen := NewEntity(p)
+	Add3(p, frozen, Position{}, Velocity{X: 1}, Frozen{})
+
+	for range 2 {
+		for _, row := range Query2[Position, Velocity](p) {
+			row.A.X += row.B.X
+			row.A.Y += row.B.Y
+		}
+	}
+	POSITION := GetStorage[Position](p)
+	if got := POSITION.Get(moving); got != (Position{X: 2, Y: 4}) {
+		t.Errorf("expected moving position {2 4}, got %v", got)
+	}
+	if got := POSITION.Get(still); got != (Position{X: 5}) {
+		t.Errorf("entity without velocity should not move, got %v", got)
+	}
+
+	var got []Entity
+	for e := range Query3[Position, Velocity, Frozen](p) {
+		got = append(got, e)
+	}
+	if !slices.Equal(got, []Entity{frozen}) {
+		t.Errorf("expected Query3 to yield only %d, got %v", frozen, got)
+	}
+	for e, pos := range Query[Position](p) {
+		if *pos != POSITION.Get(e) {
+			t.Errorf("Query pointer mismatch for %d", e)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// What happened to an entity
+type LifecycleKind uint8
+
+const (
+	EntityCreated LifecycleKind = iota
+	EntityKilled
+	ComponentAdded
+	ComponentRemoved
+)
+
+var lifecycleKindNames = [...]string{"EntityCreated", "EntityKilled", "ComponentAdded", "ComponentRemoved"}
+
+func (k LifecycleKind) String() string {
+	if int(k) < len(lifecycleKindNames) {
+		return lifecycleKindNames[k]
+	}
+	return fmt.Sprintf("LifecycleKind(%d)", k)
+}
+
+// An event sent to observers when an entity is created or killed,
+// or when it gains or loses a component
+type LifecycleEvent struct {
+	Kind   LifecycleKind
+	Entity Entity
+	// the type of the component for ComponentAdded and ComponentRemoved, nil otherwise
+	Component reflect.Type
+}
+
+// An observer is called with every lifecycle event of a pool
+type Observer func(p *Pool, event LifecycleEvent)
+
+// Register an observer for lifecycle events from [NewEntity], [Kill], [Add] and [Remove].
+//
+// Killing an entity sends ComponentRemoved for each of its components, then EntityKilled.
+// Relationship pairs and the parents set with [SetParent] do not send events.
+// Observers are called right away, in the middle of the call that caused the event,
+// unless [BufferLifecycleEvents] is turned on
+func Observe(p *Pool, observer Observer) {
+	p.observeMu.Lock()
+	defer p.observeMu.Unlock()
+	p.observers = append(p.observers, observer)
+	p.observed.Store(true)
+}
+
+// Hold lifecycle events until [FlushLifecycleEvents] is called,
+// so observers never run in the middle of changes to the pool.
+//
+// turning buffering off does not flush the held events
+func BufferLifecycleEvents(p *Pool, buffer bool) {
+	p.observeMu.Lock()
+	defer p.observeMu.Unlock()
+	p.buffering = buffer
+}
+
+// Send the held lifecycle events to the observers, in the order they happened
+func FlushLifecycleEvents(p *Pool) {
+	p.observeMu.Lock()
+	pending := p.pending
+	p.pending = nil
+	observers := p.observers
+	p.observeMu.Unlock()
+	for _, event := range pending {
+		for _, observer := range observers {
+			observer(p, event)
+		}
+	}
+}
+
+// send a lifecycle event to the observers, or hold it if buffering
+func (p *Pool) emit(kind LifecycleKind, e Entity, component reflect.Type) {
+	if !p.observed.Load() {
+		return
+	}
+	event := LifecycleEvent{Kind: kind, Entity: e, Component: component}
+	p.observeMu.Lock()
+	if p.buffering {
+		p.pending = append(p.pending, event)
+		p.observeMu.Unlock()
+		return
+	}
+	observers := p.observers
+	p.observeMu.Unlock()
+	for _, observer := range observers {
+		observer(p, event)
+	}
+}
+
+// implemented by storages that keep data for the pool itself, like relations and the hierarchy.
+// observers do not hear about their components
+type hiddenStorage interface {
+	hidden() bool
+}
+
+// send ComponentAdded or ComponentRemoved for a storage, unless it is hidden
+func (p *Pool) emitComponent(kind LifecycleKind, e Entity, st ComponentStorage) {
+	if !p.observed.Load() {
+		return
+	}
+	if h, ok := st.(hiddenStorage); ok && h.hidden() {
+		return
+	}
+	p.emit(kind, e, st.Type())
+}
@@ -0,0 +1,55 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// Test that observers see lifecycle events, right away or buffered
+func TestObservers(t *testing.T) {
+	type Position struct{}
+	type Velocity struct{}
+	p := New(4)
+	var events []LifecycleEvent
+	Observe(p, func(p *Pool, event LifecycleEvent) {
+		events = append(events, event)
+	})
+
+	e := NewEntity(p)
+	Add2(p, e, Position{}, Velocity{})
+	Add(p, e, Position{}) // already added, not an event
+	Remove[Velocity](p, e)
+	Kill(p, e)
+
+	position, velocity := reflect.TypeFor[Position](), reflect.TypeFor[Velocity]()
+	want := []LifecycleEvent{
+		{EntityCreated, e, nil},
+		{ComponentAdded, e, position},
+		{ComponentAdded, e, velocity},
+		{ComponentRemoved, e, velocity},
+		{ComponentRemoved, e, position},
+		{EntityKilled, e, nil},
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("expected %v, got %v", want, events)
+	}
+
+	events = nil
+	BufferLifecycleEvents(p, true)
+	cb := NewCommandBuffer(p)
+	spawned := cb.NewEntity()
+	DeferAdd(cb, spawned, Position{})
+	cb.Flush()
+	if len(events) != 0 {
+		t.Errorf("expected events to be held, got %v", events)
+	}
+	FlushLifecycleEvents(p)
+	want = []LifecycleEvent{
+		{EntityCreated, spawned, nil},
+		{ComponentAdded, spawned, position},
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("expected %v, got %v", want, events)
+	}
+}
@@ -0,0 +1,70 @@
+package ecs
+
+// entities per page of a paged storage
+const pageSize = 256
+
+// components split into fixed-size pages, allocated when the first
+// entity of the page gets the component and freed when the last one loses it
+type pagedData[Component any] struct {
+	pages  [][]Component // nil for pages without components
+	counts []uint16      // components stored in each page
+}
+
+func newPagedData[Component any](capacity uint32) *pagedData[Component] {
+	d := &pagedData[Component]{}
+	d.grow(capacity)
+	return d
+}
+
+func (d *pagedData[Component]) get(id uint32) Component {
+	if page := d.pages[id/pageSize]; page != nil {
+		return page[id%pageSize]
+	}
+	var zero Component
+	return zero
+}
+
+func (d *pagedData[Component]) ptr(id uint32) *Component {
+	if page := d.pages[id/pageSize]; page != nil {
+		return &page[id%pageSize]
+	}
+	return nil
+}
+
+// add is only called for entities that do not have the component yet
+func (d *pagedData[Component]) add(id uint32, c Component) {
+	pi := id / pageSize
+	if d.pages[pi] == nil {
+		d.pages[pi] = make([]Component, pageSize)
+	}
+	d.pages[pi][id%pageSize] = c
+	d.counts[pi]++
+}
+
+func (d *pagedData[Component]) set(id uint32, c Component) {
+	if page := d.pages[id/pageSize]; page != nil {
+		page[id%pageSize] = c
+	}
+}
+
+// remove is only called for entities that have the component
+func (d *pagedData[Component]) remove(id uint32) {
+	pi := id / pageSize
+	if d.pages[pi] == nil {
+		return
+	}
+	d.counts[pi]--
+	if d.counts[pi] == 0 {
+		d.pages[pi] = nil
+		return
+	}
+	var zero Component
+	d.pages[pi][id%pageSize] = zero
+}
+
+// only the page table grows, the pages are not copied
+func (d *pagedData[Component]) grow(capacity uint32) {
+	pages := (capacity + pageSize - 1) / pageSize
+	d.pages = growSlice(d.pages, pages)
+	d.counts = growSlice(d.counts, pages)
+}
@@ -0,0 +1,64 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that paged storages only allocate the pages that are used
+func TestPagedStorage(t *testing.T) {
+	type Health struct{ HP int }
+	p := New(1000)
+	HEALTH := Register[Health](p, PagedStorage)
+	data := HEALTH.components.(*pagedData[Health])
+	var es []Entity
+	for range 1000 {
+		es = append(es, NewEntity(p))
+	}
+	for _, e := range es[500:700] {
+		Add(p, e, Health{HP: int(e.Index())})
+	}
+	allocated := 0
+	for _, page := range data.pages {
+		if page != nil {
+			allocated++
+		}
+	}
+	if allocated != 2 { // indexes 501 to 700 span pages 1 and 2
+		t.Errorf("expected 2 pages, got %d", allocated)
+	}
+	if got := HEALTH.All(); !slices.Equal(got, es[500:700]) {
+		t.Errorf("expected entities 500 to 699, got %v", got)
+	}
+
+	HEALTH.GetPtr(es[650]).HP = -1
+	if HEALTH.Get(es[650]).HP != -1 || HEALTH.Get(es[10]).HP != 0 || HEALTH.GetPtr(es[10]) != nil {
+		t.Errorf("unexpected components %v %v", HEALTH.Get(es[650]), HEALTH.Get(es[10]))
+	}
+
+	// entities without the component never get a pointer into a page
+	without := es[700] // shares a page with the entities above
+	if HEALTH.GetPtr(without) != nil || HEALTH.GetPtr(es[501]) == nil {
+		t.Errorf("GetPtr should only return pointers for entities with the component")
+	}
+
+	// pointers stay valid when the pool grows
+	ptr := HEALTH.GetPtr(es[620])
+	for range 2000 {
+		NewEntity(p)
+	}
+	if ptr != HEALTH.GetPtr(es[620]) || ptr.HP != int(es[620].Index()) {
+		t.Errorf("growing the pool should not move the pages")
+	}
+
+	// pages are freed when their last component is removed
+	for _, e := range es[500:640] {
+		Remove[Health](p, e)
+	}
+	Kill(p, es[640:700]...)
+	for i, page := range data.pages {
+		if page != nil {
+			t.Errorf("expected page %d to be freed", i)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ecs
+
+import (
+	"fmt"
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Call fn for every entity that matches query (a storage or a [Filter]),
+// splitting the entities into chunks of 64 that up to workers goroutines
+// take one at a time. workers <= 0 uses GOMAXPROCS.
+//
+// fn is called once per entity, and never for the same entity on two goroutines,
+// so it is safe to read and write the components of the entity it is given,
+// eg. with [Storage.Update] or [Storage.GetPtr].
+// fn must not touch the components of other entities, create or kill entities,
+// or add or remove components. use a [CommandBuffer] for that.
+//
+// storages registered with [RegisterStorage] do not know their pool,
+// so pass them inside a [Filter].
+//
+// ForEachParallel returns once every entity has been processed
+func ForEachParallel(term Term, workers int, fn func(e Entity)) {
+	query, ok := term.(matcher)
+	if !ok {
+		panic(fmt.Sprintf("ecs: ForEachParallel cannot use %T directly, wrap it in NewFilter(p).With(...)", term))
+	}
+	p := query.owner()
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	words := len(p.entityActiveStatus.bits)
+	workers = min(workers, words)
+
+	// workers take the next word when they are done with theirs,
+	// so entities clustered in a few words do not end up on one goroutine
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				wi := int(next.Add(1) - 1)
+				if wi >= words {
+					return
+				}
+				w := query.word(wi) & p.entityActiveStatus.word(wi)
+				base := uint32(wi) * 64
+				for w != 0 {
+					t := bits.TrailingZeros64(w)
+					id := base + uint32(t)
+					fn(newEntity(id, p.generations[id]))
+					w &^= 1 << t
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,93 @@
+package ecs
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test that every matching entity is processed exactly once
+func TestForEachParallel(t *testing.T) {
+	type Position struct{ X float64 }
+	type Velocity struct{ X float64 }
+	type Frozen struct{}
+	p := New(10_000)
+	POSITION, VELOCITY, FROZEN := GetStorage3[Position, Velocity, Frozen](p)
+	for i := range 10_000 {
+		e := NewEntity(p)
+		Add2(p, e, Position{}, Velocity{X: float64(i)})
+		if i%10 == 0 {
+			Add(p, e, Frozen{})
+		}
+	}
+
+	cb := NewCommandBuffer(p)
+	var mu sync.Mutex
+	seen := make(map[Entity]int)
+	moving := NewFilter(p).With(POSITION, VELOCITY).Without(FROZEN)
+	ForEachParallel(moving, 8, func(e Entity) {
+		pos := POSITION.Get(e)
+		pos.X += VELOCITY.Get(e).X
+		POSITION.Update(e, pos)
+		VELOCITY.GetPtr(e).X = 0
+		if pos.X > 9000 {
+			cb.Kill(e)
+		}
+		mu.Lock()
+		seen[e]++
+		mu.Unlock()
+	})
+	cb.Flush()
+
+	if len(seen) != 9000 {
+		t.Errorf("expected 9000 entities to be processed, got %d", len(seen))
+	}
+	for e, n := range seen {
+		if n != 1 {
+			t.Fatalf("entity %d was processed %d times", e, n)
+		}
+	}
+	for e := range POSITION.IterAll() {
+		pos, vel := POSITION.Get(e), VELOCITY.Get(e)
+		initial := float64(e.Index() - 1)
+		if FROZEN.EntityHasComponent(e) {
+			if pos.X != 0 || vel.X != initial {
+				t.Fatalf("frozen entity %d should not be processed", e)
+			}
+		} else if pos.X != initial || vel.X != 0 {
+			t.Fatalf("entity %d was not processed", e)
+		}
+	}
+	// 9001 to 9999, except the 99 frozen ones
+	if n := len(POSITION.All()); n != 10_000-900 {
+		t.Errorf("expected 900 entities to be killed, got %d left", n)
+	}
+}
+
+// Test that neighbouring chunks of a clustered range go to different workers
+func TestForEachParallelClustered(t *testing.T) {
+	type Hot struct{}
+	p := New(64 * 16)
+	var hot []Entity
+	for i := range 64 * 16 {
+		e := NewEntity(p)
+		if i >= 64 && i < 64*3 { // fills words 1 and 2
+			Add(p, e, Hot{})
+			hot = append(hot, e)
+		}
+	}
+	// the first entity of each word waits for the other one,
+	// which only works if another goroutine has the other word
+	meet := make(chan struct{})
+	ForEachParallel(GetStorage[Hot](p), 4, func(e Entity) {
+		if e != hot[0] && e != hot[64] {
+			return
+		}
+		select {
+		case meet <- struct{}{}:
+		case <-meet:
+		case <-time.After(5 * time.Second):
+			t.Error("the words of a clustered range were given to one worker")
+		}
+	})
+}
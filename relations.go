@@ -0,0 +1,289 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// A relation stores pairs of (Relation, Target) for every source entity,
+// eg. an entity that is Targeting another entity.
+//
+// It is registered with the pool like a storage,
+// so killing a source removes its pairs, and killing a target
+// removes every pair that points to it.
+type relation[Relation any] struct {
+	pool     *Pool
+	b        *BitSet                           // sources that have at least one pair
+	modified uint64                            // bumped whenever a pair is added or removed, used by cached queries
+	targets  map[uint32][]pairTarget[Relation] // mapped from source index
+	sources  map[uint32]*pairSources           // mapped from target index
+}
+
+type pairTarget[Relation any] struct {
+	target Entity
+	value  Relation
+}
+
+// the sources that have a pair with a target.
+// most targets have few sources, so they are kept in a sorted list instead of a bitset
+type pairSources struct {
+	target Entity
+	ids    []uint32 // sorted source indexes
+}
+
+func (s *pairSources) add(source uint32) {
+	if i, found := slices.BinarySearch(s.ids, source); !found {
+		s.ids = slices.Insert(s.ids, i, source)
+	}
+}
+
+func (s *pairSources) remove(source uint32) {
+	if i, found := slices.BinarySearch(s.ids, source); found {
+		s.ids = slices.Delete(s.ids, i, i+1)
+	}
+}
+
+// the i-th group of 64 sources as a bitmask, like BitSet.word
+func (s *pairSources) word(i int) uint64 {
+	first := uint32(i) * 64
+	start, _ := slices.BinarySearch(s.ids, first)
+	var w uint64
+	for _, id := range s.ids[start:] {
+		if id >= first+64 {
+			break
+		}
+		w |= 1 << (id - first)
+	}
+	return w
+}
+
+// get the relation storage, allocate it if not already
+func getRelation[Relation any](p *Pool) *relation[Relation] {
+	nilptr := (*relation[Relation])(nil)
+
+	p.mu.RLock()
+	st, ok := p.storages[nilptr]
+	p.mu.RUnlock()
+	if ok {
+		return st.(*relation[Relation])
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if st, ok := p.storages[nilptr]; ok {
+		return st.(*relation[Relation])
+	}
+	r := &relation[Relation]{
+		pool:    p,
+		b:       NewBitSet(p.capacity),
+		targets: make(map[uint32][]pairTarget[Relation]),
+		sources: make(map[uint32]*pairSources),
+	}
+	p.storages[nilptr] = r
+	p.allStorages = append(p.allStorages, r)
+	p.unmasked = append(p.unmasked, r)
+	p.onKill = append(p.onKill, r.removeTarget)
+	return r
+}
+
+func (r *relation[Relation]) Bits() *BitSet { return r.b }
+
+func (r *relation[Relation]) Has(source Entity) bool {
+	return IsAlive(r.pool, source) && r.b.Get(source.Index())
+}
+
+func (r *relation[Relation]) Type() reflect.Type { return reflect.TypeFor[Relation]() }
+
+// pairs are not components, so observers do not hear about them
+func (r *relation[Relation]) hidden() bool { return true }
+
+// remove every pair of a source
+func (r *relation[Relation]) Clear(source Entity) {
+	id := source.Index()
+	for _, pair := range r.targets[id] {
+		r.unlink(id, pair.target)
+	}
+	delete(r.targets, id)
+	r.b.Clear(id)
+	r.modified++
+}
+
+// remove every pair that points to a killed target
+func (r *relation[Relation]) removeTarget(target Entity) {
+	s, ok := r.sources[target.Index()]
+	if !ok || s.target != target {
+		return
+	}
+	for _, source := range s.ids {
+		r.removeFromSource(source, target)
+	}
+	delete(r.sources, target.Index())
+	r.modified++
+}
+
+// remove target from the pairs of a source
+func (r *relation[Relation]) removeFromSource(source uint32, target Entity) {
+	pairs := r.targets[source]
+	for i, pair := range pairs {
+		if pair.target == target {
+			pairs = append(pairs[:i], pairs[i+1:]...)
+			break
+		}
+	}
+	if len(pairs) == 0 {
+		delete(r.targets, source)
+		r.b.Clear(source)
+		return
+	}
+	r.targets[source] = pairs
+}
+
+// remove source from the sources of a target
+func (r *relation[Relation]) unlink(source uint32, target Entity) {
+	s, ok := r.sources[target.Index()]
+	if !ok || s.target != target {
+		return
+	}
+	s.remove(source)
+	if len(s.ids) == 0 {
+		delete(r.sources, target.Index())
+	}
+}
+
+func (r *relation[Relation]) find(source, target Entity) *pairTarget[Relation] {
+	if !IsAlive(r.pool, source) {
+		return nil
+	}
+	pairs := r.targets[source.Index()]
+	for i := range pairs {
+		if pairs[i].target == target {
+			return &pairs[i]
+		}
+	}
+	return nil
+}
+
+// Add a (Relation, target) pair to source, or update its value if it already exists.
+//
+// an entity can have pairs with many targets for the same relation.
+// adding to a dead or stale source or target is a no-op
+func AddPair[Relation any](p *Pool, source, target Entity, value Relation) {
+	if !IsAlive(p, source) || !IsAlive(p, target) {
+		return
+	}
+	r := getRelation[Relation](p)
+	if pair := r.find(source, target); pair != nil {
+		pair.value = value
+		return
+	}
+	id := source.Index()
+	r.targets[id] = append(r.targets[id], pairTarget[Relation]{target: target, value: value})
+	r.b.Set(id)
+	s, ok := r.sources[target.Index()]
+	if !ok || s.target != target {
+		s = &pairSources{target: target}
+		r.sources[target.Index()] = s
+	}
+	s.add(id)
+	r.modified++
+}
+
+// Remove the (Relation, target) pair from source
+func RemovePair[Relation any](p *Pool, source, target Entity) {
+	r := getRelation[Relation](p)
+	if r.find(source, target) == nil {
+		return
+	}
+	r.removeFromSource(source.Index(), target)
+	r.unlink(source.Index(), target)
+	r.modified++
+}
+
+// Check if source has a (Relation, target) pair
+func HasPair[Relation any](p *Pool, source, target Entity) bool {
+	return getRelation[Relation](p).find(source, target) != nil
+}
+
+// Get the value of the (Relation, target) pair of source
+func GetPair[Relation any](p *Pool, source, target Entity) (Relation, bool) {
+	if pair := getRelation[Relation](p).find(source, target); pair != nil {
+		return pair.value, true
+	}
+	var zero Relation
+	return zero, false
+}
+
+// All targets that source has a Relation with, in the order they were added
+func Targets[Relation any](p *Pool, source Entity) []Entity {
+	r := getRelation[Relation](p)
+	if !IsAlive(p, source) {
+		return nil
+	}
+	pairs := r.targets[source.Index()]
+	targets := make([]Entity, len(pairs))
+	for i, pair := range pairs {
+		targets[i] = pair.target
+	}
+	return targets
+}
+
+// All entities that have a Relation with target
+func Sources[Relation any](p *Pool, target Entity) []Entity {
+	r := getRelation[Relation](p)
+	s, ok := r.sources[target.Index()]
+	if !ok || s.target != target {
+		return nil
+	}
+	sources := make([]Entity, len(s.ids))
+	for i, id := range s.ids {
+		sources[i] = newEntity(id, p.generations[id])
+	}
+	return sources
+}
+
+// Match entities that have a (Relation, target) pair.
+//
+// Use it as a term of a [Filter], eg. every enemy targeting the player:
+//
+//	ecs.NewFilter(pool).With(ENEMY, ecs.Pair[Targeting](pool, player))
+func Pair[Relation any](p *Pool, target Entity) pairFilter[Relation] {
+	return pairFilter[Relation]{r: getRelation[Relation](p), target: target}
+}
+
+// filter term returned by Pair
+type pairFilter[Relation any] struct {
+	r      *relation[Relation]
+	target Entity
+}
+
+func (f pairFilter[Relation]) word(i int) uint64 {
+	s, ok := f.r.sources[f.target.Index()]
+	if !ok || s.target != f.target {
+		return 0
+	}
+	return s.word(i)
+}
+
+func (f pairFilter[Relation]) version() uint64 { return f.r.modified }
+
+func (f pairFilter[Relation]) owner() *Pool { return f.r.pool }
+
+// check if an entity has the relation to the target
+func (f pairFilter[Relation]) Has(e Entity) bool { return matches(f, e) }
+
+// Iterate over all entities that have a (Relation, target) pair
+func (f pairFilter[Relation]) Iter() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		s, ok := f.r.sources[f.target.Index()]
+		if !ok || s.target != f.target {
+			return
+		}
+		p := f.r.pool
+		for _, id := range slices.Clone(s.ids) { // the loop may remove pairs
+			if !yield(newEntity(id, p.generations[id])) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test adding, querying and cleaning up relationship pairs
+func TestRelations(t *testing.T) {
+	type Targeting struct{}
+	type OwnedBy struct{ Since int }
+	type Enemy struct{}
+	p := New(10)
+	player, ally := NewEntity(p), NewEntity(p)
+	e1, e2, e3 := NewEntity(p), NewEntity(p), NewEntity(p)
+	Add(p, e1, Enemy{})
+	Add(p, e2, Enemy{})
+
+	AddPair(p, e1, player, Targeting{})
+	AddPair(p, e2, player, Targeting{})
+	AddPair(p, e2, ally, Targeting{})
+	AddPair(p, e3, player, Targeting{})
+	AddPair(p, e3, ally, OwnedBy{Since: 1})
+	AddPair(p, e3, ally, OwnedBy{Since: 2})
+
+	if got := Sources[Targeting](p, player); !slices.Equal(got, []Entity{e1, e2, e3}) {
+		t.Errorf("expected [%d %d %d] targeting player, got %v", e1, e2, e3, got)
+	}
+	if got := Targets[Targeting](p, e2); !slices.Equal(got, []Entity{player, ally}) {
+		t.Errorf("expected e2 to target [%d %d], got %v", player, ally, got)
+	}
+	if v, ok := GetPair[OwnedBy](p, e3, ally); !ok || v.Since != 2 {
+		t.Errorf("expected updated pair value 2, got %v %v", v, ok)
+	}
+	if HasPair[OwnedBy](p, e3, player) {
+		t.Errorf("e3 should not be owned by the player")
+	}
+
+	// pairs are filter terms
+	got := NewFilter(p).With(GetStorage[Enemy](p), Pair[Targeting](p, player)).Entities()
+	if !slices.Equal(got, []Entity{e1, e2}) {
+		t.Errorf("expected enemies [%d %d] targeting player, got %v", e1, e2, got)
+	}
+	q := NewCachedQuery(NewFilter(p).With(Pair[Targeting](p, player)))
+	q.Entities()
+
+	RemovePair[Targeting](p, e1, player)
+	if HasPair[Targeting](p, e1, player) {
+		t.Errorf("pair should be removed")
+	}
+	if got := q.Entities(); !slices.Equal(got, []Entity{e2, e3}) {
+		t.Errorf("expected cached query to see the removed pair, got %v", got)
+	}
+
+	// killing a source removes its pairs
+	Kill(p, e2)
+	if got := Sources[Targeting](p, ally); len(got) != 0 {
+		t.Errorf("expected no entities targeting ally, got %v", got)
+	}
+	// killing a target removes every pair pointing to it
+	Kill(p, player)
+	recycled := NewEntity(p)
+	if got := Sources[Targeting](p, recycled); len(got) != 0 {
+		t.Errorf("expected pairs to the killed target to be removed, got %v", got)
+	}
+	if got := Targets[Targeting](p, e3); len(got) != 0 {
+		t.Errorf("expected e3 to target nothing, got %v", got)
+	}
+	if !HasPair[OwnedBy](p, e3, ally) {
+		t.Errorf("other relations should not be affected")
+	}
+}
+
+// Test that the sources of a target are kept without a bitset per target
+func TestPairSourcesSparse(t *testing.T) {
+	type Targeting struct{}
+	type Enemy struct{}
+	p := New(1000)
+	target := NewEntity(p)
+	var sources []Entity
+	for i := range 999 {
+		e := NewEntity(p)
+		Add(p, e, Enemy{})
+		if i%100 == 63 { // spread over many words, on their edges
+			AddPair(p, e, target, Targeting{})
+			sources = append(sources, e)
+		}
+	}
+	s := getRelation[Targeting](p).sources[target.Index()]
+	if len(s.ids) != len(sources) {
+		t.Errorf("expected %d sources, got %d", len(sources), len(s.ids))
+	}
+	f := NewFilter(p).With(GetStorage[Enemy](p), Pair[Targeting](p, target))
+	if got := f.Entities(); !slices.Equal(got, sources) {
+		t.Errorf("expected %v, got %v", sources, got)
+	}
+	if got := slices.Collect(Pair[Targeting](p, target).Iter()); !slices.Equal(got, sources) {
+		t.Errorf("expected %v, got %v", sources, got)
+	}
+	RemovePair[Targeting](p, sources[0], target)
+	if got := Sources[Targeting](p, target); !slices.Equal(got, sources[1:]) {
+		t.Errorf("expected %v, got %v", sources[1:], got)
+	}
+}
+
+// Test that observers do not hear about pairs
+func TestRelationsObserved(t *testing.T) {
+	type Targeting struct{}
+	p := New(8)
+	var events []LifecycleEvent
+	Observe(p, func(_ *Pool, ev LifecycleEvent) {
+		if ev.Kind == ComponentAdded || ev.Kind == ComponentRemoved {
+			events = append(events, ev)
+		}
+	})
+	source, target, other := NewEntity(p), NewEntity(p), NewEntity(p)
+	AddPair(p, source, target, Targeting{})
+	AddPair(p, source, other, Targeting{})
+	AddPair(p, other, target, Targeting{})
+	RemovePair[Targeting](p, source, other)
+	Kill(p, other) // still has a pair
+	Kill(p, target)
+	if len(events) != 0 {
+		t.Errorf("expected no component events for pairs, got %v", events)
+	}
+
+	// a component of the same type is still observed
+	e := NewEntity(p)
+	Add(p, e, Targeting{})
+	Kill(p, e)
+	if len(events) != 2 || events[0].Kind != ComponentAdded || events[1].Kind != ComponentRemoved {
+		t.Errorf("expected added and removed events for the component, got %v", events)
+	}
+}
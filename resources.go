@@ -0,0 +1,42 @@
+package ecs
+
+// Store a resource in the pool.
+//
+// resources are singletons, there is at most one of each type per pool.
+// use them for things like delta time, input state or the camera
+// instead of package level variables.
+// if the resource already exists it is overwritten, and pointers
+// returned by [GetResource] see the new value
+func SetResource[T any](p *Pool, v T) {
+	nilptr := (*T)(nil)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r, ok := p.resources[nilptr]; ok {
+		*r.(*T) = v
+		return
+	}
+	p.resources[nilptr] = &v
+}
+
+// Get a pointer to a resource, or nil if it was never set
+func GetResource[T any](p *Pool) *T {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	r, ok := p.resources[(*T)(nil)]
+	if !ok {
+		return nil
+	}
+	return r.(*T)
+}
+
+// Check if a resource was set
+func HasResource[T any](p *Pool) bool {
+	return GetResource[T](p) != nil
+}
+
+// Remove a resource from the pool
+func RemoveResource[T any](p *Pool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.resources, (*T)(nil))
+}
@@ -0,0 +1,38 @@
+package ecs
+
+import "testing"
+
+// Test that resources are stored per pool and per type
+func TestResources(t *testing.T) {
+	type DeltaTime float64
+	type Score struct{ Points int }
+	p1, p2 := New(1), New(1)
+	if HasResource[Score](p1) || GetResource[Score](p1) != nil {
+		t.Errorf("resource should not exist before it is set")
+	}
+	SetResource(p1, Score{Points: 1})
+	SetResource(p1, DeltaTime(0.5))
+	SetResource(p2, Score{Points: 2})
+
+	score := GetResource[Score](p1)
+	score.Points += 10
+	if got := GetResource[Score](p1).Points; got != 11 {
+		t.Errorf("expected 11 points, got %d", got)
+	}
+	if got := GetResource[Score](p2).Points; got != 2 {
+		t.Errorf("expected pools to have separate resources, got %d", got)
+	}
+	if got := *GetResource[DeltaTime](p1); got != 0.5 {
+		t.Errorf("expected delta time 0.5, got %v", got)
+	}
+
+	// overwriting keeps old pointers valid
+	SetResource(p1, Score{Points: 100})
+	if score.Points != 100 {
+		t.Errorf("expected pointer to see the new value, got %d", score.Points)
+	}
+	RemoveResource[Score](p1)
+	if HasResource[Score](p1) {
+		t.Errorf("resource should not exist after it is removed")
+	}
+}
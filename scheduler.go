@@ -0,0 +1,331 @@
+package ecs
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A system is a regular function that operates on the components of a pool
+type System func(p *Pool, dt float64)
+
+// Stages group systems, and run in the order they are declared
+type Stage uint8
+
+const (
+	// runs once, the first time the scheduler runs
+	Startup Stage = iota
+	PreUpdate
+	Update
+	PostUpdate
+	Render
+	stageCount
+)
+
+var stageNames = [stageCount]string{"Startup", "PreUpdate", "Update", "PostUpdate", "Render"}
+
+func (s Stage) String() string {
+	if s < stageCount {
+		return stageNames[s]
+	}
+	return fmt.Sprintf("Stage(%d)", s)
+}
+
+type scheduledSystem struct {
+	name   string
+	run    System
+	before []string
+	after  []string
+	access map[any]access // mapped from nilptr of Component, nil if the system did not declare its access
+}
+
+// how a system uses a component type
+type access uint8
+
+const (
+	read access = 1 << iota
+	write
+)
+
+func (s *scheduledSystem) declare(key any, a access) {
+	if s.access == nil {
+		s.access = make(map[any]access)
+	}
+	s.access[key] |= a
+}
+
+// check if two systems can not run at the same time.
+// systems that did not declare their access conflict with every system
+func (s *scheduledSystem) conflicts(other *scheduledSystem) bool {
+	if s.access == nil || other.access == nil {
+		return true
+	}
+	for key, a := range s.access {
+		if b, ok := other.access[key]; ok && (a|b)&write != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Configures a system added with [Scheduler.AddSystem]
+type SystemOption func(*scheduledSystem)
+
+// Run the system before the named systems in the same stage
+func Before(names ...string) SystemOption {
+	return func(s *scheduledSystem) { s.before = append(s.before, names...) }
+}
+
+// Run the system after the named systems in the same stage
+func After(names ...string) SystemOption {
+	return func(s *scheduledSystem) { s.after = append(s.after, names...) }
+}
+
+// Declare that the system reads Component.
+//
+// systems that declare every component they use can run
+// at the same time as other systems that do not write to those components.
+// [Query], [Storage.GetPtr], [Storage.TryGetPtr] and [Storage.MarkChanged] record changes,
+// so they are writes. use [QueryRead] or [Storage.Get] to read
+func Reads[Component any]() SystemOption {
+	return func(s *scheduledSystem) { s.declare((*Component)(nil), read) }
+}
+
+// Declare that the system writes Component.
+//
+// systems that declare every component they use can run
+// at the same time as other systems that do not use those components
+func Writes[Component any]() SystemOption {
+	return func(s *scheduledSystem) { s.declare((*Component)(nil), write) }
+}
+
+// A Scheduler runs systems stage by stage.
+//
+// Within a stage, systems run in the order they were added
+// unless [Before] or [After] say otherwise.
+//
+// Systems that declare the components they use with [Reads] and [Writes]
+// run at the same time as other declared systems they do not conflict with.
+// These systems must not create or kill entities, or add or remove components,
+// use a [CommandBuffer] instead and flush it from a system that runs later.
+//
+//	s := ecs.NewScheduler()
+//	s.AddSystem(ecs.Update, "movement", movementSystem)
+//	s.AddSystem(ecs.Update, "input", inputSystem, ecs.Before("movement"))
+//	s.AddSystem(ecs.Render, "render", renderingSystem)
+//	for !rl.WindowShouldClose() {
+//		if err := s.Run(pool, dt); err != nil {
+//			panic(err)
+//		}
+//	}
+type Scheduler struct {
+	stages    [stageCount][]*scheduledSystem
+	batches   [stageCount][][]*scheduledSystem // systems in a batch can run at the same time
+	sorted    bool
+	startedUp bool
+	workers   int
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{workers: runtime.GOMAXPROCS(0)}
+}
+
+// Set how many systems can run at the same time.
+//
+// defaults to GOMAXPROCS, 1 runs every system one after the other
+func (s *Scheduler) SetWorkers(workers int) {
+	s.workers = max(workers, 1)
+}
+
+// Add a system to a stage.
+//
+// names are used by [Before] and [After], and must be unique within a stage
+func (s *Scheduler) AddSystem(stage Stage, name string, system System, options ...SystemOption) {
+	if stage >= stageCount {
+		panic(fmt.Sprintf("ecs: unknown stage %v", stage))
+	}
+	sys := &scheduledSystem{name: name, run: system}
+	for _, option := range options {
+		option(sys)
+	}
+	s.stages[stage] = append(s.stages[stage], sys)
+	s.sorted = false
+}
+
+// Run every stage once. Startup systems only run the first time.
+// This is a frame, so [UpdateEvents] is called at the end.
+//
+// returns an error without running anything if the ordering
+// constraints of a stage refer to unknown systems or form a cycle
+func (s *Scheduler) Run(p *Pool, dt float64) error {
+	if !s.sorted {
+		for stage := range stageCount {
+			batches, err := sortStage(stage, s.stages[stage])
+			if err != nil {
+				return err
+			}
+			s.batches[stage] = batches
+		}
+		s.sorted = true
+	}
+	for stage := range stageCount {
+		if stage == Startup {
+			if s.startedUp {
+				continue
+			}
+			s.startedUp = true
+		}
+		for _, batch := range s.batches[stage] {
+			s.runBatch(batch, p, dt)
+		}
+	}
+	UpdateEvents(p)
+	return nil
+}
+
+// run systems that do not conflict, using up to s.workers goroutines
+func (s *Scheduler) runBatch(batch []*scheduledSystem, p *Pool, dt float64) {
+	if len(batch) == 1 || s.workers <= 1 {
+		for _, sys := range batch {
+			sys.run(p, dt)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, s.workers)
+	for _, sys := range batch {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			sys.run(p, dt)
+			<-slots
+		}()
+	}
+	wg.Wait()
+}
+
+// order the systems of a stage so every Before and After constraint holds,
+// keeping the order they were added in otherwise.
+//
+// The ordered systems are split into batches: each system goes in the batch after
+// the last system it has to run after, or conflicts with
+func sortStage(stage Stage, systems []*scheduledSystem) ([][]*scheduledSystem, error) {
+	index := make(map[string]int, len(systems))
+	for i, sys := range systems {
+		if _, ok := index[sys.name]; ok {
+			return nil, fmt.Errorf("ecs: system %q is added to stage %v twice", sys.name, stage)
+		}
+		index[sys.name] = i
+	}
+	// edges[i] are the systems that must run after system i
+	edges := make([][]int, len(systems))
+	incoming := make([]int, len(systems))
+	for i, sys := range systems {
+		for _, name := range sys.before {
+			j, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("ecs: system %q must run before %q, which is not in stage %v", sys.name, name, stage)
+			}
+			edges[i] = append(edges[i], j)
+			incoming[j]++
+		}
+		for _, name := range sys.after {
+			j, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("ecs: system %q must run after %q, which is not in stage %v", sys.name, name, stage)
+			}
+			edges[j] = append(edges[j], i)
+			incoming[i]++
+		}
+	}
+
+	order := make([]int, 0, len(systems))
+	done := make([]bool, len(systems))
+	for len(order) < len(systems) {
+		// the first system that is not waiting on anything
+		next := -1
+		for i := range systems {
+			if !done[i] && incoming[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf("ecs: systems in stage %v have a cycle: %s", stage, findCycle(systems, edges, done))
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, j := range edges[next] {
+			incoming[j]--
+		}
+	}
+
+	var batches [][]*scheduledSystem
+	batchOf := make([]int, len(systems))
+	for k, i := range order {
+		batch := 0
+		for _, j := range order[:k] {
+			if slices.Contains(edges[j], i) || systems[i].conflicts(systems[j]) {
+				batch = max(batch, batchOf[j]+1)
+			}
+		}
+		batchOf[i] = batch
+		if batch == len(batches) {
+			batches = append(batches, nil)
+		}
+		batches[batch] = append(batches[batch], systems[i])
+	}
+	return batches, nil
+}
+
+// describe a cycle among the systems that are not done, eg. "a -> b -> a"
+func findCycle(systems []*scheduledSystem, edges [][]int, done []bool) string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(systems))
+	var path []int
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		path = append(path, i)
+		for _, j := range edges[i] {
+			if done[j] {
+				continue
+			}
+			if state[j] == visiting {
+				start := 0
+				for path[start] != j {
+					start++
+				}
+				return append(path[start:], j)
+			}
+			if state[j] == unvisited {
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range systems {
+		if done[i] || state[i] != unvisited {
+			continue
+		}
+		if cycle := visit(i); cycle != nil {
+			names := make([]string, len(cycle))
+			for k, j := range cycle {
+				names[k] = systems[j].name
+			}
+			return strings.Join(names, " -> ")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package ecs
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test that stages run in order, constraints are respected and startup runs once
+func TestScheduler(t *testing.T) {
+	var ran []string
+	system := func(name string) System {
+		return func(p *Pool, dt float64) { ran = append(ran, name) }
+	}
+	s := NewScheduler()
+	s.AddSystem(Render, "render", system("render"))
+	s.AddSystem(Update, "collision", system("collision"), After("movement"))
+	s.AddSystem(Update, "movement", system("movement"))
+	s.AddSystem(Update, "input", system("input"), Before("movement"))
+	s.AddSystem(PreUpdate, "spawn", system("spawn"))
+	s.AddSystem(Startup, "setup", system("setup"))
+
+	p := New(1)
+	for range 2 {
+		if err := s.Run(p, 1.0/60); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"setup", "spawn", "input", "movement", "collision", "render",
+		"spawn", "input", "movement", "collision", "render",
+	}
+	if !slices.Equal(ran, want) {
+		t.Errorf("expected %v, got %v", want, ran)
+	}
+}
+
+// Test that bad constraints are reported instead of running systems
+func TestSchedulerErrors(t *testing.T) {
+	noop := func(p *Pool, dt float64) {}
+	p := New(1)
+
+	s := NewScheduler()
+	s.AddSystem(Update, "a", noop, Before("b"))
+	s.AddSystem(Update, "b", noop, Before("c"))
+	s.AddSystem(Update, "c", noop, Before("a"))
+	err := s.Run(p, 0)
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("expected cycle a -> b -> c -> a, got %v", err)
+	}
+
+	s = NewScheduler()
+	s.AddSystem(Update, "a", noop, After("missing"))
+	if err := s.Run(p, 0); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("expected error about missing system, got %v", err)
+	}
+
+	s = NewScheduler()
+	s.AddSystem(Update, "a", noop)
+	s.AddSystem(Update, "a", noop)
+	if err := s.Run(p, 0); err == nil {
+		t.Errorf("expected error about duplicate system")
+	}
+}
+
+// Test that systems with disjoint access run at the same time,
+// and systems that conflict do not
+func TestSchedulerParallel(t *testing.T) {
+	type Position struct{ X float64 }
+	type Velocity struct{ X float64 }
+	type Health struct{ HP int }
+	p := New(100)
+	for range 100 {
+		Add3(p, NewEntity(p), Position{}, Velocity{X: 1}, Health{HP: 10})
+	}
+
+	// movement and damage only run if they run at the same time
+	started := make(chan struct{})
+	rendezvous := func() {
+		select {
+		case started <- struct{}{}:
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Error("systems with disjoint access did not run at the same time")
+		}
+	}
+	var writingPosition atomic.Int32
+	movement := func(p *Pool, dt float64) {
+		rendezvous()
+		writingPosition.Add(1)
+		defer writingPosition.Add(-1)
+		for _, c := range Query2[Position, Velocity](p) {
+			c.A.X += c.B.X * dt
+		}
+	}
+	damage := func(p *Pool, dt float64) {
+		rendezvous()
+		for _, hp := range Query[Health](p) {
+			hp.HP--
+		}
+	}
+	var total float64
+	sum := func(p *Pool, dt float64) {
+		if writingPosition.Load() != 0 {
+			t.Error("reader of Position ran at the same time as its writer")
+		}
+		for _, pos := range Query[Position](p) {
+			total += pos.X
+		}
+	}
+
+	s := NewScheduler()
+	s.SetWorkers(4)
+	s.AddSystem(Update, "movement", movement, Reads[Velocity](), Writes[Position]())
+	s.AddSystem(Update, "damage", damage, Writes[Health]())
+	s.AddSystem(Update, "sum", sum, Reads[Position]())
+	if err := s.Run(p, 1); err != nil {
+		t.Fatal(err)
+	}
+	if total != 100 {
+		t.Errorf("expected positions to sum to 100, got %v", total)
+	}
+	for e := range GetStorage[Health](p).IterAll() {
+		if hp := GetStorage[Health](p).Get(e).HP; hp != 9 {
+			t.Fatalf("expected 9 hp, got %d", hp)
+		}
+	}
+}
+
+// Test that readers can run at the same time while changes are tracked.
+// run with -race
+func TestSchedulerReadersWithChangeTracking(t *testing.T) {
+	type Position struct{ X float64 }
+	p := New(100)
+	for range 100 {
+		Add(p, NewEntity(p), Position{X: 1})
+	}
+	Changed[Position](p, 0) // turn on change tracking
+	var sums [2]float64
+	reader := func(i int) System {
+		return func(p *Pool, dt float64) {
+			for _, pos := range QueryRead[Position](p) {
+				sums[i] += pos.X
+			}
+		}
+	}
+	s := NewScheduler()
+	s.SetWorkers(4)
+	s.AddSystem(Update, "a", reader(0), Reads[Position]())
+	s.AddSystem(Update, "b", reader(1), Reads[Position]())
+	for range 10 {
+		if err := s.Run(p, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sums[0] != 1000 || sums[1] != 1000 {
+		t.Errorf("expected both readers to sum to 1000, got %v", sums)
+	}
+}
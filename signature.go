@@ -0,0 +1,106 @@
+package ecs
+
+import "math/bits"
+
+// A Signature is a set of components that is checked against an entity in one go.
+//
+//	moving := ecs.NewSignature(pool, POSITION, VELOCITY)
+//	if moving.Matches(e) {
+//		...
+//	}
+//
+// Every entity keeps a mask of its components, with one bit per [Storage.ID],
+// so a signature is checked a word at a time instead of a storage at a time.
+// Storages registered with [RegisterStorage] are not in the masks,
+// so they are checked one by one.
+type Signature struct {
+	pool     *Pool
+	mask     []uint64
+	unmasked []ComponentStorage
+}
+
+// Create a signature that matches entities that have all the components of storages
+func NewSignature(p *Pool, storages ...ComponentStorage) *Signature {
+	s := &Signature{pool: p}
+	for _, st := range storages {
+		m, ok := st.(maskedStorage)
+		if !ok {
+			s.unmasked = append(s.unmasked, st)
+			continue
+		}
+		id := m.maskID()
+		for len(s.mask) <= id/64 {
+			s.mask = append(s.mask, 0)
+		}
+		s.mask[id/64] |= 1 << (id % 64)
+	}
+	return s
+}
+
+// Check if an entity has every component of the signature
+//
+// dead or stale entities never match
+func (s *Signature) Matches(e Entity) bool {
+	if !IsAlive(s.pool, e) {
+		return false
+	}
+	mask := s.pool.mask(e.Index())
+	for i, want := range s.mask {
+		if mask[i]&want != want {
+			return false
+		}
+	}
+	for _, st := range s.unmasked {
+		if !st.Bits().Get(e.Index()) {
+			return false
+		}
+	}
+	return true
+}
+
+// implemented by storages that have a bit in the entity masks
+type maskedStorage interface {
+	maskID() int
+}
+
+func (s *Storage[Component]) maskID() int { return s.ID }
+
+// the component mask of an entity index
+func (p *Pool) mask(id uint32) []uint64 {
+	return p.masks[id*p.maskWords : (id+1)*p.maskWords]
+}
+
+// make room for n storages in the masks.
+// must be called with the write lock held
+func (p *Pool) growMasks(n int) {
+	words := uint32((n + 63) / 64)
+	if words <= p.maskWords {
+		return
+	}
+	masks := make([]uint64, p.capacity*words)
+	for id := range p.capacity {
+		copy(masks[id*words:], p.mask(id))
+	}
+	p.masks, p.maskWords = masks, words
+}
+
+// clear every component of an entity that was just killed
+func (p *Pool) clearComponents(e Entity) {
+	id := e.Index()
+	for wi, w := range p.mask(id) {
+		for w != 0 {
+			t := bits.TrailingZeros64(w)
+			st := p.allStorages[wi*64+t]
+			st.Clear(e)
+			p.emitComponent(ComponentRemoved, e, st)
+			w &^= 1 << t
+		}
+	}
+	for _, st := range p.unmasked {
+		if st.Bits().Get(id) { // skip zeroing if no bit
+			st.Clear(e)
+			st.Bits().Clear(id) // custom storages may leave the bit to the pool
+			p.emitComponent(ComponentRemoved, e, st)
+		}
+	}
+}
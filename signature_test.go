@@ -0,0 +1,78 @@
+package ecs
+
+import "testing"
+
+type maskComp[T any] struct{ V int }
+
+// register 8 storages at once, so the tests can make more than 64 of them
+func registerMany[T any](p *Pool, e Entity) {
+	Add(p, e, maskComp[[1]T]{1})
+	Add(p, e, maskComp[[2]T]{2})
+	Add(p, e, maskComp[[3]T]{3})
+	Add(p, e, maskComp[[4]T]{4})
+	Add(p, e, maskComp[[5]T]{5})
+	Add(p, e, maskComp[[6]T]{6})
+	Add(p, e, maskComp[[7]T]{7})
+	Add(p, e, maskComp[[8]T]{8})
+}
+
+func TestComponentMask(t *testing.T) {
+	type Position struct{}
+	type Velocity struct{}
+	type Name string
+	p := New(10)
+	POSITION, VELOCITY := GetStorage[Position](p), GetStorage[Velocity](p)
+	NAME := newMapStorage[Name]()
+	RegisterStorage(p, NAME)
+	e1, e2 := NewEntity(p), NewEntity(p)
+	Add(p, e1, Position{})
+	Add(p, e1, Velocity{})
+	Add(p, e2, Position{})
+
+	moving := NewSignature(p, POSITION, VELOCITY)
+	if !moving.Matches(e1) || moving.Matches(e2) {
+		t.Errorf("only e1 should match")
+	}
+
+	// more than 64 storages need a second mask word
+	registerMany[int8](p, e2)
+	registerMany[int16](p, e2)
+	registerMany[int32](p, e2)
+	registerMany[int64](p, e2)
+	registerMany[uint8](p, e2)
+	registerMany[uint16](p, e2)
+	registerMany[uint32](p, e2)
+	registerMany[uint64](p, e2)
+	Add(p, e2, Velocity{})
+	Add(p, e2, Name("e2"))
+	if p.maskWords != 2 {
+		t.Fatalf("expected 2 mask words, got %d", p.maskWords)
+	}
+	last := GetStorage[maskComp[[8]uint64]](p)
+	named := NewSignature(p, last, POSITION, NAME)
+	if !named.Matches(e2) || named.Matches(e1) || !moving.Matches(e2) {
+		t.Errorf("the masks should survive growing")
+	}
+
+	removed := 0
+	Observe(p, func(_ *Pool, ev LifecycleEvent) {
+		if ev.Kind == ComponentRemoved {
+			removed++
+		}
+	})
+	Remove[Position](p, e2)
+	if moving.Matches(e2) {
+		t.Errorf("removing a component should clear its bit")
+	}
+	Kill(p, e2)
+	if removed != 67 { // Position, Velocity, Name and 64 others
+		t.Errorf("expected 67 components removed, got %d", removed)
+	}
+	if last.b.Count() != 0 || NAME.b.Count() != 0 {
+		t.Errorf("Kill should clear every storage")
+	}
+	e3 := NewEntity(p) // reuses the slot of e2
+	if named.Matches(e3) || NewSignature(p, POSITION).Matches(e3) {
+		t.Errorf("a recycled entity should have no components")
+	}
+}
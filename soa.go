@@ -0,0 +1,157 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// A SoA stores each field of a struct component in its own slice
+// (struct of arrays), so loops over one field only read that field.
+//
+//	type Position struct{ X, Y, Z float64 }
+//	POSITION := ecs.RegisterSoA[Position](pool)
+//	xs := ecs.Column[float64](POSITION, "X")
+//	for e := range POSITION.Iter() {
+//		xs[e.Index()] += 1
+//	}
+//
+// It works with [Add], [Remove], [Kill] and filters like any other storage,
+// get it back with [GetComponentStorage].
+// Hooks and change detection are not supported.
+type SoA[Component any] struct {
+	b        *BitSet
+	pool     *Pool
+	columns  []reflect.Value // a slice for each field, indexed by Entity.Index
+	names    map[string]int  // index of each field in columns
+	modified uint64          // bumped when an entity gains or loses this component
+}
+
+// Register Component with a struct of arrays storage, and get the storage.
+//
+// Component must be a struct with only exported fields.
+// panics if Component already has another kind of storage
+func RegisterSoA[Component any](p *Pool) *SoA[Component] {
+	if st := GetComponentStorage[Component](p); st != nil {
+		s, ok := st.(*SoA[Component])
+		if !ok {
+			panic(fmt.Sprintf("ecs: %v already has a storage that is not a SoA", st.Type()))
+		}
+		return s
+	}
+	t := reflect.TypeFor[Component]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("ecs: SoA needs a struct component, %v is a %v", t, t.Kind()))
+	}
+	s := &SoA[Component]{
+		b:     NewBitSet(0),
+		pool:  p,
+		names: make(map[string]int, t.NumField()),
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			panic(fmt.Sprintf("ecs: SoA needs exported fields, %v.%s is not", t, f.Name))
+		}
+		s.names[f.Name] = i
+		s.columns = append(s.columns, reflect.MakeSlice(reflect.SliceOf(f.Type), 0, 0))
+	}
+	if err := RegisterStorage(p, s); err != nil { // registered by another goroutine
+		return RegisterSoA[Component](p)
+	}
+	return s
+}
+
+// Get the slice holding one field of every entity, indexed by [Entity.Index].
+//
+// Only entities in [SoA.Bits] have the component, the other slots are zero.
+// The slice is replaced when the pool grows, so do not keep it around.
+// panics if the field does not exist or is not of type Field
+func Column[Field, Component any](s *SoA[Component], name string) []Field {
+	i, ok := s.names[name]
+	if !ok {
+		panic(fmt.Sprintf("ecs: %v has no field %s", s.Type(), name))
+	}
+	column, ok := s.columns[i].Interface().([]Field)
+	if !ok {
+		panic(fmt.Sprintf("ecs: field %s of %v is a %v, not a %v",
+			name, s.Type(), s.columns[i].Type().Elem(), reflect.TypeFor[Field]()))
+	}
+	return column
+}
+
+// the bitset of entities that have this component, indexed by [Entity.Index]
+func (s *SoA[Component]) Bits() *BitSet { return s.b }
+
+// check if an entity has this component
+func (s *SoA[Component]) Has(e Entity) bool { return IsAlive(s.pool, e) && s.b.Get(e.Index()) }
+
+// the type of component stored
+func (s *SoA[Component]) Type() reflect.Type { return reflect.TypeFor[Component]() }
+
+// zero out the fields of an entity. use [Remove] instead
+func (s *SoA[Component]) Clear(e Entity) {
+	id := int(e.Index())
+	for _, column := range s.columns {
+		column.Index(id).SetZero()
+	}
+	s.b.Clear(e.Index())
+	s.modified++
+}
+
+// scatter the fields of c into the columns, used by [Add].
+//
+// Set does not add the component, so only call it directly
+// for entities that already have it
+func (s *SoA[Component]) Set(e Entity, c Component) {
+	id := int(e.Index())
+	v := reflect.ValueOf(c)
+	for i, column := range s.columns {
+		column.Index(id).Set(v.Field(i))
+	}
+	if !s.b.Get(e.Index()) { // called by Add for a new component
+		s.modified++
+	}
+}
+
+// gather the fields of an entity into a component
+//
+// returns the zero value for dead or stale entities
+func (s *SoA[Component]) Get(e Entity) Component {
+	var c Component
+	if !IsAlive(s.pool, e) {
+		return c
+	}
+	id := int(e.Index())
+	v := reflect.ValueOf(&c).Elem()
+	for i, column := range s.columns {
+		v.Field(i).Set(column.Index(id))
+	}
+	return c
+}
+
+// Iterate over all entities that have this component
+func (s *SoA[Component]) Iter() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		bits := s.b.Clone()
+		defer bits.Release()
+		s.pool.each(bits, yield)
+	}
+}
+
+func (s *SoA[Component]) grow(capacity uint32) {
+	for i, column := range s.columns {
+		if column.Len() >= int(capacity) {
+			continue
+		}
+		grown := reflect.MakeSlice(column.Type(), int(capacity), int(capacity))
+		reflect.Copy(grown, column)
+		s.columns[i] = grown
+	}
+}
+
+func (s *SoA[Component]) word(i int) uint64 { return s.b.word(i) }
+
+func (s *SoA[Component]) version() uint64 { return s.modified }
+
+func (s *SoA[Component]) owner() *Pool { return s.pool }
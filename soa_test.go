@@ -0,0 +1,95 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSoA(t *testing.T) {
+	type Position struct{ X, Y, Z float64 }
+	type Frozen struct{}
+	p := New(10)
+	POSITION := RegisterSoA[Position](p)
+	if RegisterSoA[Position](p) != POSITION || GetComponentStorage[Position](p) != POSITION {
+		t.Fatalf("RegisterSoA should return the registered storage")
+	}
+	e1, e2, e3 := NewEntity(p), NewEntity(p), NewEntity(p)
+	Add(p, e1, Position{1, 2, 3})
+	Add(p, e2, Position{4, 5, 6})
+	Add(p, e3, Frozen{})
+	if got := POSITION.Get(e2); got != (Position{4, 5, 6}) {
+		t.Errorf("expected {4 5 6}, got %v", got)
+	}
+
+	xs := Column[float64](POSITION, "X")
+	for e := range POSITION.Iter() {
+		xs[e.Index()] *= 10
+	}
+	if POSITION.Get(e1).X != 10 || POSITION.Get(e2).X != 40 || POSITION.Get(e1).Y != 2 {
+		t.Errorf("writing the column should change the components, got %v %v", POSITION.Get(e1), POSITION.Get(e2))
+	}
+
+	f := NewCachedQuery(NewFilter(p).With(POSITION).Without(GetStorage[Frozen](p)))
+	if got := f.Entities(); !slices.Equal(got, []Entity{e1, e2}) {
+		t.Errorf("expected [%d %d], got %v", e1, e2, got)
+	}
+	Remove[Position](p, e1)
+	Kill(p, e2)
+	if got := f.Entities(); len(got) != 0 {
+		t.Errorf("expected no entities, got %v", got)
+	}
+	if xs[e2.Index()] != 0 {
+		t.Errorf("killing should zero the fields")
+	}
+
+	// the columns grow with the pool
+	for range 100 {
+		NewEntity(p)
+	}
+	e := NewEntity(p)
+	Add(p, e, Position{7, 8, 9})
+	if got := Column[float64](POSITION, "Z")[e.Index()]; got != 9 {
+		t.Errorf("expected 9, got %v", got)
+	}
+}
+
+func TestColumnPanics(t *testing.T) {
+	type Position struct{ X, Y float64 }
+	p := New(10)
+	POSITION := RegisterSoA[Position](p)
+	for _, column := range []func(){
+		func() { Column[float64](POSITION, "W") },
+		func() { Column[int](POSITION, "X") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic")
+				}
+			}()
+			column()
+		}()
+	}
+}
+
+// Test that updating a SoA component does not invalidate cached queries
+func TestSoAVersion(t *testing.T) {
+	type Position struct{ X float64 }
+	p := New(10)
+	POSITION := RegisterSoA[Position](p)
+	e := NewEntity(p)
+	Add(p, e, Position{1})
+	v := POSITION.version()
+	Add(p, e, Position{2})
+	POSITION.Set(e, Position{3})
+	if POSITION.version() != v {
+		t.Errorf("updating a component should not bump the version")
+	}
+	if POSITION.Get(e).X != 3 {
+		t.Errorf("expected X to be 3, got %v", POSITION.Get(e).X)
+	}
+	Add(p, NewEntity(p), Position{})
+	if POSITION.version() == v {
+		t.Errorf("adding a component should bump the version")
+	}
+}
@@ -0,0 +1,67 @@
+package ecs
+
+// components packed together in a dense slice,
+// with a sparse index from entity to position in the dense slice
+type sparseData[Component any] struct {
+	dense    []Component
+	denseIDs []uint32 // entity index of each dense component
+	sparse   []uint32 // position+1 in dense for each entity, 0 if not stored
+}
+
+func newSparseData[Component any](capacity uint32) *sparseData[Component] {
+	return &sparseData[Component]{sparse: make([]uint32, capacity)}
+}
+
+func (d *sparseData[Component]) get(id uint32) Component {
+	if i := d.sparse[id]; i != 0 {
+		return d.dense[i-1]
+	}
+	var zero Component
+	return zero
+}
+
+func (d *sparseData[Component]) ptr(id uint32) *Component {
+	if i := d.sparse[id]; i != 0 {
+		return &d.dense[i-1]
+	}
+	return nil
+}
+
+func (d *sparseData[Component]) add(id uint32, c Component) {
+	if i := d.sparse[id]; i != 0 {
+		d.dense[i-1] = c
+		return
+	}
+	d.dense = append(d.dense, c)
+	d.denseIDs = append(d.denseIDs, id)
+	d.sparse[id] = uint32(len(d.dense))
+}
+
+func (d *sparseData[Component]) set(id uint32, c Component) {
+	if i := d.sparse[id]; i != 0 {
+		d.dense[i-1] = c
+	}
+}
+
+// move the last component into the hole, so the dense slice stays packed
+func (d *sparseData[Component]) remove(id uint32) {
+	i := d.sparse[id]
+	if i == 0 {
+		return
+	}
+	last := len(d.dense) - 1
+	lastID := d.denseIDs[last]
+	d.dense[i-1] = d.dense[last]
+	d.denseIDs[i-1] = lastID
+	d.sparse[lastID] = i
+	d.sparse[id] = 0
+
+	var zero Component
+	d.dense[last] = zero
+	d.dense = d.dense[:last]
+	d.denseIDs = d.denseIDs[:last]
+}
+
+func (d *sparseData[Component]) grow(capacity uint32) {
+	d.sparse = growSlice(d.sparse, capacity)
+}
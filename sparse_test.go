@@ -0,0 +1,62 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that sparse storages behave like dense storages
+func TestSparseStorage(t *testing.T) {
+	type BossAI struct{ Phase int }
+	type Position struct{}
+	p := New(100)
+	BOSS := Register[BossAI](p, SparseStorage)
+	if GetStorage[BossAI](p) != BOSS {
+		t.Fatalf("GetStorage should return the registered storage")
+	}
+	var es []Entity
+	for range 100 {
+		e := NewEntity(p)
+		Add(p, e, Position{})
+		es = append(es, e)
+	}
+	b1, b2, b3 := es[10], es[50], es[90]
+	Add(p, b1, BossAI{Phase: 1})
+	Add(p, b2, BossAI{Phase: 2})
+	Add(p, b3, BossAI{Phase: 3})
+	data := BOSS.components.(*sparseData[BossAI])
+	if len(data.dense) != 3 {
+		t.Errorf("expected 3 packed components, got %d", len(data.dense))
+	}
+
+	BOSS.Update(b2, BossAI{Phase: 20})
+	BOSS.GetPtr(b3).Phase = 30
+	BOSS.Update(es[0], BossAI{Phase: 99}) // does not have the component
+	if BOSS.GetPtr(es[0]) != nil || BOSS.Get(es[0]).Phase != 0 {
+		t.Errorf("entity without the component should not be stored")
+	}
+	got := BOSS.And(GetStorage[Position](p))
+	if !slices.Equal(got, []Entity{b1, b2, b3}) {
+		t.Errorf("expected [%d %d %d], got %v", b1, b2, b3, got)
+	}
+
+	// removing keeps the other components in place
+	Remove[BossAI](p, b1)
+	if BOSS.Get(b2).Phase != 20 || BOSS.Get(b3).Phase != 30 || BOSS.Get(b1).Phase != 0 {
+		t.Errorf("unexpected components after remove: %v %v %v", BOSS.Get(b1), BOSS.Get(b2), BOSS.Get(b3))
+	}
+	Kill(p, b3)
+	if len(data.dense) != 1 || BOSS.Get(b2).Phase != 20 {
+		t.Errorf("expected only b2 to be left, got %v", data.dense)
+	}
+
+	// sparse storages grow with the pool
+	for range 200 {
+		NewEntity(p)
+	}
+	e := NewEntity(p)
+	Add(p, e, BossAI{Phase: 4})
+	if BOSS.Get(e).Phase != 4 {
+		t.Errorf("expected phase 4 after growing, got %d", BOSS.Get(e).Phase)
+	}
+}
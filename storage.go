@@ -1,73 +1,336 @@
 package ecs
 
-func newStorage[Component any](capacity uint32) (s *Storage[Component]) {
-	return &Storage[Component]{
-		components: make([]Component, capacity),
-		b:          newBitset(capacity),
+import (
+	"iter"
+	"math/bits"
+	"reflect"
+)
+
+// How a storage keeps its components
+type StorageKind uint8
+
+const (
+	// one slot per entity, the fastest to access (default)
+	DenseStorage StorageKind = iota
+	// only the bitset, for marker components. see [RegisterTag]
+	TagStorage
+	// components packed together, for components that few entities have.
+	// uses memory for the components that exist, plus 4 bytes per entity
+	SparseStorage
+	// components split into pages of 256 entities, allocated when needed.
+	// for components that are clustered on some entity indexes,
+	// growing the pool does not copy the components
+	PagedStorage
+)
+
+func newStorage[Component any](capacity uint32, kind StorageKind) (s *Storage[Component]) {
+	s = &Storage[Component]{
+		b:    NewBitSet(capacity),
+		kind: kind,
+	}
+	switch kind {
+	case TagStorage:
+		s.components = tagData[Component]{}
+	case SparseStorage:
+		s.components = newSparseData[Component](capacity)
+	case PagedStorage:
+		s.components = newPagedData[Component](capacity)
+	default:
+		s.components = &denseData[Component]{components: make([]Component, capacity)}
 	}
+	return s
 }
 
-func (s *Storage[Component]) EntityHasComponent(e Entity) bool {
-	return s.b.Get(e)
+// Where a storage keeps its components.
+// the storage bitset decides which entities have the component
+type componentData[Component any] interface {
+	get(id uint32) Component    // the zero value if not stored
+	ptr(id uint32) *Component   // nil if not stored
+	add(id uint32, c Component) // store the component, making room if needed. only called if not added yet
+	set(id uint32, c Component) // overwrite the component, can be ignored if not stored
+	remove(id uint32)           // forget or zero the component. only called if added
+	grow(capacity uint32)       // make room for more entities
 }
 
-func (s *Storage[Component]) bits() *bitSet { return s.b }
+// one slot per entity
+type denseData[Component any] struct {
+	components []Component
+}
 
-type storage interface {
-	bits() *bitSet
-	clear(Entity) // zero out the component for this entity
+func (d *denseData[Component]) get(id uint32) Component    { return d.components[id] }
+func (d *denseData[Component]) ptr(id uint32) *Component   { return &d.components[id] }
+func (d *denseData[Component]) add(id uint32, c Component) { d.components[id] = c }
+func (d *denseData[Component]) set(id uint32, c Component) { d.components[id] = c }
+func (d *denseData[Component]) remove(id uint32) {
+	var zero Component
+	d.components[id] = zero
+}
+func (d *denseData[Component]) grow(capacity uint32) {
+	d.components = growSlice(d.components, capacity)
 }
 
-// zero out the components for this entity
-// does nothing if the entity is alive
-func (s *Storage[Component]) clear(e Entity) {
-	s.bits().Clear(e)
+// nothing is stored, only the bitset
+type tagData[Component any] struct{}
+
+func (tagData[Component]) get(id uint32) Component {
 	var zero Component
-	s.components[e] = zero
+	return zero
+}
+func (tagData[Component]) ptr(id uint32) *Component   { return nil }
+func (tagData[Component]) add(id uint32, c Component) {}
+func (tagData[Component]) set(id uint32, c Component) {}
+func (tagData[Component]) remove(id uint32)           {}
+func (tagData[Component]) grow(capacity uint32)       {}
+
+// check if an entity has this component
+//
+// stale entities never have components
+func (s *Storage[Component]) EntityHasComponent(e Entity) bool {
+	return IsAlive(s.pool, e) && s.b.Get(e.Index())
+}
+
+// the bitset of entities that have this component, indexed by [Entity.Index]
+func (s *Storage[Component]) Bits() *BitSet { return s.b }
+
+// check if an entity has this component, same as [Storage.EntityHasComponent]
+func (s *Storage[Component]) Has(e Entity) bool { return s.EntityHasComponent(e) }
+
+// the type of component stored
+func (s *Storage[Component]) Type() reflect.Type { return reflect.TypeFor[Component]() }
+
+func (s *Storage[Component]) word(i int) uint64 { return s.b.word(i) }
+
+func (s *Storage[Component]) version() uint64 { return s.modified }
+
+func (s *Storage[Component]) owner() *Pool { return s.pool }
+
+func (s *Storage[Component]) hidden() bool { return s.internal }
+
+// zero out the component for this entity, calling OnRemove hooks first
+// if the entity has the component.
+// this does not check if the entity is alive, because it is called by Kill.
+//
+// use [Remove] instead, which also notifies observers
+func (s *Storage[Component]) Clear(e Entity) {
+	id := e.Index()
+	if !s.b.Get(id) { // only zero the slot, it was never added
+		var zero Component
+		s.components.set(id, zero)
+		return
+	}
+	for _, hook := range s.onRemove {
+		hook(s.pool, e, s.components.get(id))
+	}
+	s.components.remove(id)
+	s.b.Clear(id)
+	s.pool.mask(id)[s.ID/64] &^= 1 << (s.ID % 64)
+	s.modified++
 }
 
-// update the component of an entity. this does not check if the entity is alive
+// make room for more entities, keeping the existing components
+func (s *Storage[Component]) grow(capacity uint32) {
+	s.components.grow(capacity)
+	if s.changed != nil {
+		s.added = growSlice(s.added, capacity)
+		s.changed = growSlice(s.changed, capacity)
+	}
+}
+
+// return a slice of length n with the contents of s,
+// or s itself if it is already long enough
+func growSlice[T any](s []T, n uint32) []T {
+	if uint32(len(s)) >= n {
+		return s
+	}
+	grown := make([]T, n)
+	copy(grown, s)
+	return grown
+}
+
+// update the component of an entity.
+//
+// updating a dead or stale entity is a no-op.
+// sparse and paged storages may ignore updates to entities that do not have the component
 func (s *Storage[Component]) Update(e Entity, c Component) {
-	s.components[e] = c
+	if !IsAlive(s.pool, e) {
+		return
+	}
+	id := e.Index()
+	s.components.set(id, c)
+	s.markChanged(id)
+	if s.b.Get(id) {
+		for _, hook := range s.onSet {
+			hook(s.pool, e, c)
+		}
+	}
+}
+
+// pointer to the component slot of an entity index, nil for tag storages.
+// does not check if the entity is alive or has the component.
+// the component is assumed to be changed through the pointer,
+// so this is a write for the scheduler
+func (s *Storage[Component]) ptr(id uint32) *Component {
+	s.markChanged(id)
+	return s.components.ptr(id)
 }
 
 // get a copy of a component
-// this does not check if the entity is alive
+//
+// returns the zero value for dead or stale entities
 func (s *Storage[Component]) Get(e Entity) Component {
-	return s.components[e]
+	if !IsAlive(s.pool, e) {
+		var zero Component
+		return zero
+	}
+	return s.components.get(e.Index())
+}
+
+// get a pointer to the component of an entity, so it can be modified in place.
+// this does not check if the entity has the component
+//
+// returns nil for dead or stale entities, for tag storages,
+// and for sparse and paged storages if the entity does not have the component.
+// the pointer is invalidated when the pool grows, so do not keep it around.
+// getting the pointer counts as a change for [Changed]
+func (s *Storage[Component]) GetPtr(e Entity) *Component {
+	if !IsAlive(s.pool, e) {
+		return nil
+	}
+	// a page can be shared with entities that have the component,
+	// but writes to the slot would be lost when the page is freed
+	if s.kind == PagedStorage && !s.b.Get(e.Index()) {
+		return nil
+	}
+	return s.ptr(e.Index())
+}
+
+// get a pointer to the component of an entity, so it can be modified in place.
+//
+// returns nil, false if the entity is dead, stale, or does not have the component.
+// returns nil, true for tag storages if the entity has the tag
+func (s *Storage[Component]) TryGetPtr(e Entity) (*Component, bool) {
+	if !s.EntityHasComponent(e) {
+		return nil, false
+	}
+	return s.ptr(e.Index()), true
 }
 
 // All entities that have this component
 func (s *Storage[Component]) All() []Entity {
-	return s.b.ActiveIDs()
+	return s.pool.entities(s.b)
 }
 
 // All entities that have this component and the other components
-func (s *Storage[Component]) And(others ...storage) []Entity {
+func (s *Storage[Component]) And(others ...ComponentStorage) []Entity {
 	bits := s.b.Clone()
 	defer bits.Release()
 	for _, s2 := range others {
-		bits.And(s2.bits())
+		bits.And(s2.Bits())
 	}
-	return bits.ActiveIDs()
+	return s.pool.entities(bits)
 }
 
 // All entities that have this component but not the other components
-func (s *Storage[Component]) ButNot(others ...storage) []Entity {
+func (s *Storage[Component]) ButNot(others ...ComponentStorage) []Entity {
 	bits := s.b.Clone()
 	defer bits.Release()
 	for _, s2 := range others {
-		bits.AndNot(s2.bits())
+		bits.AndNot(s2.Bits())
 	}
-	return bits.ActiveIDs()
+	return s.pool.entities(bits)
 }
 
 // All entities that have either components
-func (s *Storage[Component]) Or(others ...storage) []Entity {
+func (s *Storage[Component]) Or(others ...ComponentStorage) []Entity {
 	bits := s.b.Clone()
 	defer bits.Release()
 	for _, s2 := range others {
-		bits.Or(s2.bits())
+		bits.Or(s2.Bits())
+	}
+	return s.pool.entities(bits)
+}
+
+// Iterate over all entities that have this component.
+//
+// Unlike [Storage.All] this does not allocate a slice of entities
+func (s *Storage[Component]) IterAll() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		bits := s.b.Clone()
+		defer bits.Release()
+		s.pool.each(bits, yield)
+	}
+}
+
+// Iterate over all entities that have this component and the other components
+//
+// Unlike [Storage.And] this does not allocate a slice of entities
+func (s *Storage[Component]) IterAnd(others ...ComponentStorage) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		bits := s.b.Clone()
+		defer bits.Release()
+		for _, s2 := range others {
+			bits.And(s2.Bits())
+		}
+		s.pool.each(bits, yield)
+	}
+}
+
+// Iterate over all entities that have this component but not the other components
+//
+// Unlike [Storage.ButNot] this does not allocate a slice of entities
+func (s *Storage[Component]) IterButNot(others ...ComponentStorage) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		bits := s.b.Clone()
+		defer bits.Release()
+		for _, s2 := range others {
+			bits.AndNot(s2.Bits())
+		}
+		s.pool.each(bits, yield)
+	}
+}
+
+// Iterate over all entities that have either components
+//
+// Unlike [Storage.Or] this does not allocate a slice of entities
+func (s *Storage[Component]) IterOr(others ...ComponentStorage) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		bits := s.b.Clone()
+		defer bits.Release()
+		for _, s2 := range others {
+			bits.Or(s2.Bits())
+		}
+		s.pool.each(bits, yield)
+	}
+}
+
+// call yield with every set bit of b as an entity with its current generation.
+// returns false if yield asked to stop
+func (p *Pool) each(b *BitSet, yield func(Entity) bool) bool {
+	for wi, w := range b.bits {
+		base := uint32(wi) * 64
+		for w != 0 {
+			t := bits.TrailingZeros64(w)
+			id := base + uint32(t)
+			if !yield(newEntity(id, p.generations[id])) {
+				return false
+			}
+			w &^= 1 << t
+		}
+	}
+	return true
+}
+
+// turn the set bits of b into entities with their current generation
+func (p *Pool) entities(b *BitSet) []Entity {
+	entities := make([]Entity, 0, b.Count())
+	for wi, w := range b.bits {
+		base := uint32(wi) * 64
+		for w != 0 {
+			t := bits.TrailingZeros64(w)
+			id := base + uint32(t)
+			entities = append(entities, newEntity(id, p.generations[id]))
+			w &^= 1 << t
+		}
 	}
-	return bits.ActiveIDs()
+	return entities
 }
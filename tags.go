@@ -0,0 +1,35 @@
+package ecs
+
+// Register Component as a tag, and get its storage.
+//
+// Tag storages only store which entities have the component (the bitset),
+// not the components themselves, so they use almost no memory.
+// Use them for markers like Player or Frozen.
+// They work in queries like any other storage, but [Storage.Get] always returns
+// the zero value and pointers to the component are nil.
+//
+// if Component is already registered as a normal storage, that storage is returned
+func RegisterTag[Component any](p *Pool) *Storage[Component] {
+	return Register[Component](p, TagStorage)
+}
+
+// Add a tag to an entity, registering it if not already
+//
+// adding to a dead or stale entity is a no-op
+func AddTag[Tag any](p *Pool, e Entity) {
+	RegisterTag[Tag](p)
+	var zero Tag
+	Add(p, e, zero)
+}
+
+// Remove a tag from an entity
+//
+// removing from a dead or stale entity is a no-op
+func RemoveTag[Tag any](p *Pool, e Entity) {
+	Remove[Tag](p, e)
+}
+
+// Check if an entity has a tag, registering it if not already
+func HasTag[Tag any](p *Pool, e Entity) bool {
+	return RegisterTag[Tag](p).EntityHasComponent(e)
+}
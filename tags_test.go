@@ -0,0 +1,75 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that tags do not store components but work in queries
+func TestTags(t *testing.T) {
+	type Rock int
+	type Frozen struct{}
+	type Position struct{ X int }
+	p := New(10)
+	ROCK := RegisterTag[Rock](p)
+	if _, ok := ROCK.components.(tagData[Rock]); !ok {
+		t.Errorf("tag storage should not allocate components")
+	}
+	rock, frozenRock, player := NewEntity(p), NewEntity(p), NewEntity(p)
+	Add(p, rock, Rock(5))
+	AddTag[Rock](p, frozenRock)
+	AddTag[Frozen](p, frozenRock)
+	Add2(p, player, Position{}, Frozen{})
+	if _, ok := GetStorage[Frozen](p).components.(tagData[Frozen]); !ok {
+		t.Errorf("AddTag should register a tag storage")
+	}
+
+	if !HasTag[Rock](p, rock) || HasTag[Rock](p, player) {
+		t.Errorf("HasTag mismatch")
+	}
+	if got := ROCK.Get(rock); got != 0 {
+		t.Errorf("tags should not store values, got %d", got)
+	}
+	if ptr, ok := ROCK.TryGetPtr(rock); ptr != nil || !ok {
+		t.Errorf("expected nil, true from TryGetPtr on a tag, got %v, %v", ptr, ok)
+	}
+	FROZEN := GetStorage[Frozen](p)
+	if got := ROCK.And(FROZEN); !slices.Equal(got, []Entity{frozenRock}) {
+		t.Errorf("expected And to return [%d], got %v", frozenRock, got)
+	}
+	if got := ROCK.ButNot(FROZEN); !slices.Equal(got, []Entity{rock}) {
+		t.Errorf("expected ButNot to return [%d], got %v", rock, got)
+	}
+	if got := ROCK.Or(FROZEN); !slices.Equal(got, []Entity{rock, frozenRock, player}) {
+		t.Errorf("expected Or to return all 3 entities, got %v", got)
+	}
+
+	RemoveTag[Rock](p, rock)
+	Kill(p, frozenRock)
+	if n := len(ROCK.All()); n != 0 {
+		t.Errorf("expected no rocks, got %d", n)
+	}
+	for range 20 { // tag storages grow with the pool
+		AddTag[Rock](p, NewEntity(p))
+	}
+	if n := len(ROCK.All()); n != 20 {
+		t.Errorf("expected 20 rocks after growing, got %d", n)
+	}
+}
+
+// Test that checking a tag before adding it does not make it a dense storage
+func TestHasTagBeforeAddTag(t *testing.T) {
+	type Frozen struct{}
+	p := New(4)
+	e := NewEntity(p)
+	if HasTag[Frozen](p, e) {
+		t.Errorf("the entity should not have the tag yet")
+	}
+	AddTag[Frozen](p, e)
+	if !HasTag[Frozen](p, e) {
+		t.Errorf("the entity should have the tag")
+	}
+	if _, ok := GetStorage[Frozen](p).components.(tagData[Frozen]); !ok {
+		t.Errorf("expected a tag storage, got %T", GetStorage[Frozen](p).components)
+	}
+}